@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runDept 将多位教师已生成的月报或学期报合并为部门报告
+func runDept(args []string) {
+	fs := flag.NewFlagSet("dept", flag.ExitOnError)
+	outputDir := fs.String("o", "", locale.T("部门报告的输出目录"))
+	reportType := fs.String("t", "m", locale.T("合并的报告类型 (m: 月报, s: 学期报)"))
+	period := fs.String("p", "", locale.T("时间段 (月报: YYYYMM, 学期报: YYYY - YYYY 春/秋)，不指定时列出可选的时间段"))
+	sidecar := fs.Bool("json", false, locale.T("同时输出与报告同名的 .json 数据文件"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	if *outputDir == "" {
+		log.Fatal(locale.T("错误：必须指定输出目录 (-o)"))
+	}
+
+	// 每个参数为“名称=目录”，省略名称时以目录名作为名称
+	var teachers []reportgen.Teacher
+	for _, arg := range fs.Args() {
+		name, dir, ok := strings.Cut(arg, "=")
+		if !ok {
+			dir = arg
+			name = filepath.Base(filepath.Clean(arg))
+		}
+		teachers = append(teachers, reportgen.Teacher{Name: name, WorkDir: dir})
+	}
+
+	department, err := reportgen.NewDepartment(teachers, *outputDir, locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+	department.Backup = reportgen.NewBackup(*outputDir, 0)
+	department.Sidecar = *sidecar
+
+	selected := []string{*period}
+	if *period == "" {
+		periods, err := department.Periods(*reportType)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if selected, err = choosePeriods(periods); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, p := range selected {
+		if err := department.Generate(*reportType, p); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println(locale.T("报告生成完成"))
+}
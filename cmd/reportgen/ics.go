@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runICS 将日报杂事部分中的查宿、监考、值班等任务导出为 iCalendar 文件
+func runICS(args []string) {
+	fs := flag.NewFlagSet("ics", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	output := fs.String("o", "", locale.T("输出文件，默认输出到标准输出"))
+	name := fs.String("name", "", locale.T("日历名称"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	_, terms := loadWorkingDir(*dirPath, *lang)
+
+	// 与 query 子命令相同的查询语句用于限定日期范围等
+	query, err := reportgen.ParseQueryArgs(fs.Args(), locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := reportgen.CollectEntries(filepath.Join(*dirPath, terms.DailyDir), locale)
+	if err != nil {
+		log.Fatal(locale.Errorf("读取日报文件失败：%v", err))
+	}
+	duties := reportgen.ExtractDuties(query.Filter(entries), terms)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := reportgen.WriteICS(w, duties, *name); err != nil {
+		log.Fatal(err)
+	}
+	if *output != "" {
+		fmt.Println(locale.Sprintf("已导出 %d 项任务到 %s", len(duties), *output))
+	}
+}
@@ -3,18 +3,44 @@ reportgen
 linux darwin windows
 生成报告
 用法: reportgen [选项]
+      reportgen verify -d 目录    检查各级报告的一致性
+      reportgen serve -d 目录 [-addr 127.0.0.1:8080] [-f]    启动本地网页界面
+      reportgen query -d 目录 [-o md|csv|json] 查询语句    查询日报中的记录
+          查询语句示例: section:教学 course:机械制图 tag:#实训 semester:"2024 - 2025 秋" from:2024-09-01 to:2024-12-31 三视图 -tag:#理论
+      reportgen ics -d 目录 [-o 文件.ics] [-name 日历名称] [查询语句]    将查宿、监考、值班等任务导出为日历
+      reportgen roster -d 目录 -r 排班表.xlsx|.csv -teacher 姓名 [-sheet 工作表] (-m YYYYMM | -s 学期)    核对排班表与日报中的任务
+      reportgen undo -d 目录    撤销最近一次生成，恢复被覆盖的报告
+      reportgen dept -o 输出目录 [-t m|s] [-p 时间段] [-json] 张三=目录 李四=目录 ...    合并多位教师的月报或学期报
 
 选项:
   -d string
         指定工作目录
   -f    是否格式化内容
+  -from string
+        自定义时间段的起始日期 (格式: YYYY-MM-DD)
   -h    显示帮助信息
+  -json
+        同时输出与报告同名的 .json 数据文件
+  -lang string
+        界面语言 (zh-CN, en)，默认读取 LANG
+  -llm string
+        大模型对话接口地址 (如 http://localhost:11434/api/chat)，指定后生成总结部分
+  -llm-model string
+        大模型名称 (default "qwen2.5")
+  -llm-timeout duration
+        大模型接口超时时间 (default 1m0s)
   -m string
         指定月份 (格式: YYYYMM)
+  -n string
+        自定义时间段名称 (配置文件中的 ranges，或与 -from/-to 一同指定)
   -s string
-        指定学期 (格式: YYYY - YYYY 春/秋)
+        指定学期 (格式: YYYY - YYYY 春/秋)，也用于教学统计
+  -source string
+        笔记来源：目录、.zip 归档或 git:版本 (如 git:HEAD~1)，默认为工作目录
   -t string
-        指定报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报)
+        指定报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报, c: 自定义时间段, a: 教学统计)
+  -to string
+        自定义时间段的结束日期 (格式: YYYY-MM-DD)
   -v    显示版本号
   -w string
         指定周数
@@ -27,11 +53,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -41,23 +66,58 @@ import (
 // 版本号，默认值 "dev"，在编译时通过 -ldflags 动态设置
 var version = "dev"
 
+// 界面语言，启动时从环境变量读取，可通过 -lang 或配置文件覆盖
+var locale = reportgen.DetectLocale()
+
+// subcommands 子命令，第一个参数为子命令名称时执行
+var subcommands = map[string]func(args []string){
+	"verify": runVerify,
+	"serve":  runServe,
+	"query":  runQuery,
+	"dept":   runDept,
+	"undo":   runUndo,
+	"ics":    runICS,
+	"roster": runRoster,
+}
+
 func main() {
+	// 执行子命令
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	// 定义命令行参数
-	dirPath := flag.String("d", "", "指定工作目录")
-	reportType := flag.String("t", "", "指定报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报)")
-	formatting := flag.Bool("f", false, "是否格式化内容")
-	week := flag.String("w", "", "指定周数")
-	month := flag.String("m", "", "指定月份 (格式: YYYYMM)")
-	semester := flag.String("s", "", "指定学期 (格式: YYYY - YYYY 春/秋)")
-	year := flag.String("y", "", "指定年份 (格式: YYYY)")
-	help := flag.Bool("h", false, "显示帮助信息")
-	showVersion := flag.Bool("v", false, "显示版本号")
+	dirPath := flag.String("d", "", locale.T("指定工作目录"))
+	reportType := flag.String("t", "", locale.T("指定报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报, c: 自定义时间段, a: 教学统计)"))
+	formatting := flag.Bool("f", false, locale.T("是否格式化内容"))
+	week := flag.String("w", "", locale.T("指定周数"))
+	month := flag.String("m", "", locale.T("指定月份 (格式: YYYYMM)"))
+	semester := flag.String("s", "", locale.T("指定学期 (格式: YYYY - YYYY 春/秋)，也用于教学统计"))
+	year := flag.String("y", "", locale.T("指定年份 (格式: YYYY)"))
+	rangeName := flag.String("n", "", locale.T("自定义时间段名称 (配置文件中的 ranges，或与 -from/-to 一同指定)"))
+	rangeFrom := flag.String("from", "", locale.T("自定义时间段的起始日期 (格式: YYYY-MM-DD)"))
+	rangeTo := flag.String("to", "", locale.T("自定义时间段的结束日期 (格式: YYYY-MM-DD)"))
+	help := flag.Bool("h", false, locale.T("显示帮助信息"))
+	showVersion := flag.Bool("v", false, locale.T("显示版本号"))
+	lang := flag.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	llmEndpoint := flag.String("llm", "", locale.T("大模型对话接口地址 (如 http://localhost:11434/api/chat)，指定后生成总结部分"))
+	llmModel := flag.String("llm-model", "qwen2.5", locale.T("大模型名称"))
+	source := flag.String("source", "", locale.T("笔记来源：目录、.zip 归档或 git:版本 (如 git:HEAD~1)，默认为工作目录"))
+	sidecar := flag.Bool("json", false, locale.T("同时输出与报告同名的 .json 数据文件"))
+	llmTimeout := flag.Duration("llm-timeout", reportgen.DefaultLLMTimeout, locale.T("大模型接口超时时间"))
 
 	flag.Parse()
 
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+
 	// 显示版本号
 	if *showVersion {
-		fmt.Println("reportgen 版本:", version)
+		fmt.Println(locale.T("reportgen 版本:"), version)
 		return
 	}
 
@@ -67,14 +127,23 @@ func main() {
 		return
 	}
 
-	// 检查工作目录
-	if *dirPath == "" {
-		log.Fatal("错误：必须指定工作目录 (-d)")
-	}
-
-	// 验证工作目录结构
-	if err := reportgen.ValidateWorkingDir(*dirPath); err != nil {
-		log.Fatal(err)
+	// 检查并读取工作目录，指定笔记来源时检查笔记来源
+	var settings *reportgen.Settings
+	var terms *reportgen.Terms
+	var sourceFS fs.FS
+	if *source == "" {
+		settings, terms = loadWorkingDir(*dirPath, *lang)
+	} else {
+		settings, terms = loadSettings(*dirPath, *lang)
+		opened, err := reportgen.OpenSource(*source, *dirPath, terms, locale)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer opened.Close()
+		sourceFS = opened
+		if err := reportgen.ValidateSource(sourceFS, terms, locale); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	// 如果未指定报告类型，提供选择
@@ -88,8 +157,22 @@ func main() {
 
 	// 创建配置
 	config := &reportgen.Config{
+		WorkDir:    *dirPath,
 		ReportType: *reportType,
 		Formatting: *formatting,
+		Locale:     locale,
+		Terms:      terms,
+		Ranges:     settings.Ranges,
+		Sidecar:    *sidecar,
+		Backup:     reportgen.NewBackup(*dirPath, settings.BackupKeep),
+		Source:     sourceFS,
+	}
+	if *llmEndpoint != "" {
+		config.LLM = &reportgen.LLMConfig{
+			Endpoint: *llmEndpoint,
+			Model:    *llmModel,
+			Timeout:  *llmTimeout,
+		}
 	}
 
 	// 创建生成器
@@ -101,8 +184,8 @@ func main() {
 	// 根据报告类型设置源目录和目标目录
 	switch *reportType {
 	case "w":
-		config.SourceDir = filepath.Join(*dirPath, "日报")
-		config.TargetDir = filepath.Join(*dirPath, "周报")
+		config.SourceDir = filepath.Join(*dirPath, terms.DailyDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.WeeklyDir)
 		if *week == "" {
 			selected, err := selectPeriod(config)
 			if err != nil {
@@ -120,8 +203,8 @@ func main() {
 		config.SelectedPeriod = *week
 
 	case "m":
-		config.SourceDir = filepath.Join(*dirPath, "周报")
-		config.TargetDir = filepath.Join(*dirPath, "月报")
+		config.SourceDir = filepath.Join(*dirPath, terms.WeeklyDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.MonthlyDir)
 		if *month == "" {
 			selected, err := selectPeriod(config)
 			if err != nil {
@@ -139,8 +222,8 @@ func main() {
 		config.SelectedPeriod = *month
 
 	case "s":
-		config.SourceDir = filepath.Join(*dirPath, "月报")
-		config.TargetDir = filepath.Join(*dirPath, "学期报")
+		config.SourceDir = filepath.Join(*dirPath, terms.MonthlyDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.SemesterDir)
 		if *semester == "" {
 			selected, err := selectPeriod(config)
 			if err != nil {
@@ -158,8 +241,8 @@ func main() {
 		config.SelectedPeriod = *semester
 
 	case "y":
-		config.SourceDir = filepath.Join(*dirPath, "学期报")
-		config.TargetDir = filepath.Join(*dirPath, "年报")
+		config.SourceDir = filepath.Join(*dirPath, terms.SemesterDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.YearlyDir)
 		if *year == "" {
 			selected, err := selectPeriod(config)
 			if err != nil {
@@ -176,8 +259,55 @@ func main() {
 		}
 		config.SelectedPeriod = *year
 
+	case "a":
+		config.SourceDir = filepath.Join(*dirPath, terms.DailyDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.AnalyticsDir)
+		if *semester == "" {
+			selected, err := selectPeriod(config)
+			if err != nil {
+				log.Fatal(err)
+			}
+			// 对每个选中的时间段生成报告
+			for _, period := range selected {
+				config.SelectedPeriod = period
+				if err := generator.Generate(config.SourceDir, nil); err != nil {
+					log.Fatal(err)
+				}
+			}
+			return
+		}
+		config.SelectedPeriod = *semester
+
+	case "c":
+		config.SourceDir = filepath.Join(*dirPath, terms.DailyDir)
+		config.TargetDir = filepath.Join(*dirPath, terms.CustomDir)
+		if *rangeFrom != "" || *rangeTo != "" {
+			// 命令行指定的时间段，未命名时以起止日期命名
+			r := reportgen.NamedRange{Name: *rangeName, Start: *rangeFrom, End: *rangeTo}
+			if r.Name == "" {
+				r.Name = fmt.Sprintf("%s - %s", *rangeFrom, *rangeTo)
+			}
+			config.Ranges = append(config.Ranges, r)
+			*rangeName = r.Name
+		}
+		if *rangeName == "" {
+			selected, err := selectPeriod(config)
+			if err != nil {
+				log.Fatal(err)
+			}
+			// 对每个选中的时间段生成报告
+			for _, period := range selected {
+				config.SelectedPeriod = period
+				if err := generator.Generate(config.SourceDir, nil); err != nil {
+					log.Fatal(err)
+				}
+			}
+			return
+		}
+		config.SelectedPeriod = *rangeName
+
 	default:
-		log.Fatal("不支持的报告类型：", *reportType)
+		log.Fatal(locale.Errorf("不支持的报告类型：%s", *reportType))
 	}
 
 	// 创建生成器
@@ -191,26 +321,57 @@ func main() {
 		log.Fatal(err)
 	}
 
-	fmt.Println("报告生成完成")
+	fmt.Println(locale.T("报告生成完成"))
+}
+
+// loadWorkingDir 读取工作目录下的配置文件并验证目录结构，-lang 未指定时使用配置文件中的界面语言
+func loadWorkingDir(dirPath, lang string) (*reportgen.Settings, *reportgen.Terms) {
+	settings, terms := loadSettings(dirPath, lang)
+	if err := reportgen.ValidateWorkingDir(dirPath, terms, locale); err != nil {
+		log.Fatal(err)
+	}
+	return settings, terms
+}
+
+// loadSettings 读取工作目录下的配置文件，-lang 未指定时使用配置文件中的界面语言
+func loadSettings(dirPath, lang string) (*reportgen.Settings, *reportgen.Terms) {
+	if dirPath == "" {
+		log.Fatal(locale.T("错误：必须指定工作目录 (-d)"))
+	}
+
+	settings, err := reportgen.LoadSettings(dirPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if lang == "" && settings.Locale != "" {
+		locale = reportgen.ParseLocale(settings.Locale)
+	}
+	terms, err := settings.ResolveTerms()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return settings, terms
 }
 
 func selectReportType() (string, error) {
 	reportTypes := []string{
-		"归纳周报 (w)",
-		"归纳月报 (m)",
-		"归纳学期报 (s)",
-		"归纳年报 (y)",
+		locale.T("归纳周报 (w)"),
+		locale.T("归纳月报 (m)"),
+		locale.T("归纳学期报 (s)"),
+		locale.T("归纳年报 (y)"),
+		locale.T("归纳自定义时间段 (c)"),
+		locale.T("统计教学情况 (a)"),
 	}
 
 	var selected string
 	prompt := &survey.Select{
-		Message: "请选择要生成的报告类型：",
+		Message: locale.T("请选择要生成的报告类型："),
 		Options: reportTypes,
 	}
 
 	err := survey.AskOne(prompt, &selected)
 	if err != nil {
-		return "", fmt.Errorf("选择报告类型失败：%v", err)
+		return "", locale.Errorf("选择报告类型失败：%v", err)
 	}
 
 	return strings.ToLower(string(selected[len(selected)-2])), nil
@@ -228,41 +389,49 @@ func selectPeriod(config *reportgen.Config) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return choosePeriods(periods)
+}
 
+// choosePeriods 让用户从时间段中多选，返回选中时间段的 Key
+func choosePeriods(periods []reportgen.Period) ([]string, error) {
 	if len(periods) == 0 {
-		return nil, fmt.Errorf("未找到可用的时间段")
+		return nil, locale.Errorf("未找到可用的时间段")
 	}
 
-	// 对时间段进行排序
-	sort.Slice(periods, func(i, j int) bool {
-		// 提取数字部分
-		ni, _ := strconv.Atoi(strings.TrimLeft(periods[i], "第"))
-		nj, _ := strconv.Atoi(strings.TrimLeft(periods[j], "第"))
-		return ni < nj
-	})
+	// 时间段已按时间先后排序，以描述作为选项并映射回时间段
+	options := make([]string, len(periods))
+	keys := make(map[string]string, len(periods))
+	for i, period := range periods {
+		options[i] = period.Describe(locale)
+		keys[options[i]] = period.Key
+	}
 
 	var selected []string
 	prompt := &survey.MultiSelect{
-		Message: "请选择时间段（空格键选择，回车键确认）：",
-		Options: periods,
+		Message: locale.T("请选择时间段（空格键选择，回车键确认）："),
+		Options: options,
 	}
 
-	err = survey.AskOne(prompt, &selected)
+	err := survey.AskOne(prompt, &selected)
 	if err != nil {
-		return nil, fmt.Errorf("选择时间段失败：%v", err)
+		return nil, locale.Errorf("选择时间段失败：%v", err)
 	}
 
 	if len(selected) == 0 {
-		return nil, fmt.Errorf("未选择任何时间段")
+		return nil, locale.Errorf("未选择任何时间段")
 	}
 
-	return selected, nil
+	selectedKeys := make([]string, len(selected))
+	for i, option := range selected {
+		selectedKeys[i] = keys[option]
+	}
+	return selectedKeys, nil
 }
 
 func printHelp() {
-	fmt.Println("生成报告")
-	fmt.Println("用法: reportgen [选项]")
+	fmt.Println(locale.T("生成报告"))
+	fmt.Println(locale.T("用法: reportgen [选项]"))
 	fmt.Println()
-	fmt.Println("选项:")
+	fmt.Println(locale.T("选项:"))
 	flag.PrintDefaults()
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runQuery 按条件查询日报中的记录
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	format := fs.String("o", "md", locale.T("输出格式 (md, csv, json)"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	_, terms := loadWorkingDir(*dirPath, *lang)
+
+	query, err := reportgen.ParseQueryArgs(fs.Args(), locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := reportgen.CollectEntries(filepath.Join(*dirPath, terms.DailyDir), locale)
+	if err != nil {
+		log.Fatal(locale.Errorf("读取日报文件失败：%v", err))
+	}
+
+	if err := reportgen.WriteEntries(os.Stdout, query.Filter(entries), *format, locale); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runRoster 核对学校排班表与日报中记录的查宿、监考等任务，发现问题时以状态码 1 退出
+func runRoster(args []string) {
+	fs := flag.NewFlagSet("roster", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	rosterPath := fs.String("r", "", locale.T("排班表文件 (.xlsx 或 .csv)"))
+	sheet := fs.String("sheet", "", locale.T("排班表所在的工作表，默认为第一个工作表"))
+	teacher := fs.String("teacher", "", locale.T("排班表中的教师姓名"))
+	month := fs.String("m", "", locale.T("指定月份 (格式: YYYYMM)"))
+	semester := fs.String("s", "", locale.T("指定学期 (格式: YYYY - YYYY 春/秋)"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	if *rosterPath == "" || *teacher == "" {
+		log.Fatal(locale.Errorf("请使用 -r 指定排班表，-teacher 指定教师姓名"))
+	}
+
+	// 核对的时间段
+	var start, end time.Time
+	switch {
+	case *month != "":
+		date, err := time.Parse("200601", *month)
+		if err != nil {
+			log.Fatal(locale.Errorf("无效的月份：%s", *month))
+		}
+		start, end = date, date.AddDate(0, 1, -1)
+	case *semester != "":
+		var err error
+		if start, end, err = reportgen.SemesterRange(*semester); err != nil {
+			log.Fatal(locale.Errorf("无效的学期：%s", *semester))
+		}
+	default:
+		log.Fatal(locale.Errorf("请使用 -m 指定月份或 -s 指定学期"))
+	}
+
+	_, terms := loadWorkingDir(*dirPath, *lang)
+
+	roster, err := reportgen.LoadRoster(*rosterPath, *sheet, locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+	entries, err := reportgen.CollectEntries(filepath.Join(*dirPath, terms.DailyDir), locale)
+	if err != nil {
+		log.Fatal(locale.Errorf("读取日报文件失败：%v", err))
+	}
+	duties := reportgen.ExtractDuties(entries, terms)
+
+	issues := reportgen.ReconcileRoster(roster, duties, *teacher, start, end, terms)
+	if len(issues) == 0 {
+		fmt.Println(locale.T("未发现问题"))
+		return
+	}
+	reportgen.WriteRosterIssues(os.Stdout, issues, locale)
+	os.Exit(1)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runServe 在本地启动浏览和生成报告的网页界面
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	addr := fs.String("addr", "127.0.0.1:8080", locale.T("监听地址"))
+	formatting := fs.Bool("f", false, locale.T("是否格式化内容"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	settings, terms := loadWorkingDir(*dirPath, *lang)
+
+	server := reportgen.NewServer(reportgen.Config{
+		WorkDir:    *dirPath,
+		Formatting: *formatting,
+		Locale:     locale,
+		Terms:      terms,
+		Ranges:     settings.Ranges,
+		Backup:     reportgen.NewBackup(*dirPath, settings.BackupKeep),
+	}, *addr)
+
+	fmt.Println(locale.Sprintf("请在浏览器中打开 http://%s/", *addr))
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
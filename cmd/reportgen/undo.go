@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// runUndo 撤销最近一次生成，恢复被覆盖的报告并删除新建的报告
+func runUndo(args []string) {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	// 部门报告的输出目录也可以撤销，因此不检查目录结构
+	if *dirPath == "" {
+		log.Fatal(locale.T("错误：必须指定工作目录 (-d)"))
+	}
+
+	entries, err := reportgen.Undo(*dirPath, locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Existed {
+			fmt.Println(locale.Sprintf("已恢复 %s", entry.Path))
+		} else {
+			fmt.Println(locale.Sprintf("已删除 %s", entry.Path))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mrered/gobin/pkg/reportgen"
+)
+
+// issueTitles 各类问题的标题，按输出顺序排列
+var issueTitles = []struct {
+	kind  string
+	title string
+}{
+	{reportgen.IssueOrphan, "未被覆盖的日报"},
+	{reportgen.IssueCrossMonth, "跨月周报"},
+	{reportgen.IssueDuplicate, "重复计入"},
+	{reportgen.IssueStale, "过期的报告"},
+	{reportgen.IssueCounter, "统计数据不一致"},
+	{reportgen.IssueEncoding, "无法解码的文件"},
+}
+
+// runVerify 检查各级报告之间的一致性，发现问题时以状态码 1 退出
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dirPath := fs.String("d", "", locale.T("指定工作目录"))
+	lang := fs.String("lang", "", locale.T("界面语言 (zh-CN, en)，默认读取 LANG"))
+	fs.Parse(args)
+
+	if *lang != "" {
+		locale = reportgen.ParseLocale(*lang)
+	}
+	_, terms := loadWorkingDir(*dirPath, *lang)
+
+	issues, err := reportgen.Verify(*dirPath, terms, locale)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(issues) == 0 {
+		fmt.Println(locale.T("未发现问题"))
+		return
+	}
+
+	for _, group := range issueTitles {
+		var lines []string
+		for _, issue := range issues {
+			if issue.Kind == group.kind {
+				lines = append(lines, locale.Sprintf("  %s：%s", issue.File, issue.Message))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Printf("%s (%d)\n", locale.T(group.title), len(lines))
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(locale.Sprintf("共发现 %d 个问题", len(issues)))
+	os.Exit(1)
+}
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 长度换算为 Word 使用的缇（twip，1/20 磅）
+const (
+	twipsPerPt = 20
+	twipsPerIn = 1440
+	twipsPerCm = 1440 / 2.54
+)
+
+// paperSizes 常用纸张纵向的宽和高，单位为缇
+var paperSizes = map[string][2]int{
+	"a3":        {16838, 23811},
+	"a4":        {11906, 16838},
+	"a5":        {8391, 11906},
+	"iso-b5":    {9979, 14175},
+	"us-letter": {12240, 15840},
+}
+
+// docx 的固定部件
+const (
+	docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+</Types>`
+	docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+	docxNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+)
+
+// lengthTwips 将 Typst 长度（如 2.3cm、12pt）换算为缇，auto 和 fr 等相对长度返回 false
+func lengthTwips(length string) (int, bool) {
+	length = strings.TrimSpace(length)
+	for _, unit := range []struct {
+		suffix string
+		twips  float64
+	}{{"cm", twipsPerCm}, {"mm", twipsPerCm / 10}, {"pt", twipsPerPt}, {"in", twipsPerIn}} {
+		if number, ok := strings.CutSuffix(length, unit.suffix); ok {
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, false
+			}
+			return int(value*unit.twips + 0.5), true
+		}
+	}
+	return 0, false
+}
+
+// columnWidths 按样式中的列宽分配表格宽度，auto 和 fr 列平分固定列之外的宽度
+func columnWidths(specs []string, total int) []int {
+	widths := make([]int, len(specs))
+	remaining, flexible := total, 0
+	for i, spec := range specs {
+		if twips, ok := lengthTwips(spec); ok {
+			widths[i] = twips
+			remaining -= twips
+		} else {
+			flexible++
+		}
+	}
+	for i := range widths {
+		if widths[i] == 0 && flexible > 0 {
+			widths[i] = max(remaining/flexible, twipsPerPt*28) // 至少约 1cm
+		}
+	}
+	return widths
+}
+
+// xmlText 转义 XML 文本
+func xmlText(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// runProps 文本段的格式
+type runProps struct {
+	bold, italic, strike, code, link bool
+	size                             int // 字号，单位为半磅，0 表示默认字号
+}
+
+// docxWriter 生成 Word 文档正文，记录正文中用到的超链接
+type docxWriter struct {
+	body  strings.Builder
+	links []string
+	style Style
+}
+
+// run 生成一个文本段
+func (w *docxWriter) run(text string, props runProps) string {
+	var rPr strings.Builder
+	if props.code {
+		rPr.WriteString(`<w:rFonts w:ascii="Courier New" w:hAnsi="Courier New"/>`)
+	}
+	if props.bold {
+		rPr.WriteString(`<w:b/>`)
+	}
+	if props.italic {
+		rPr.WriteString(`<w:i/>`)
+	}
+	if props.strike {
+		rPr.WriteString(`<w:strike/>`)
+	}
+	// 子元素须按 CT_RPr 规定的顺序：color 在 sz、szCs 之前，u 在其后
+	if props.link {
+		rPr.WriteString(`<w:color w:val="0563C1"/>`)
+	}
+	if props.size > 0 {
+		rPr.WriteString(fmt.Sprintf(`<w:sz w:val="%d"/><w:szCs w:val="%d"/>`, props.size, props.size))
+	}
+	if props.link {
+		rPr.WriteString(`<w:u w:val="single"/>`)
+	}
+	var sb strings.Builder
+	sb.WriteString("<w:r>")
+	if rPr.Len() > 0 {
+		sb.WriteString("<w:rPr>" + rPr.String() + "</w:rPr>")
+	}
+	sb.WriteString(`<w:t xml:space="preserve">` + xmlText(text) + "</w:t></w:r>")
+	return sb.String()
+}
+
+// runs 将行内元素转为文本段
+func (w *docxWriter) runs(nodes []inlineNode, props runProps) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		inner := props
+		switch node.Kind {
+		case inlineText:
+			sb.WriteString(w.run(node.Text, props))
+			continue
+		case inlineCode:
+			inner.code = true
+			sb.WriteString(w.run(node.Text, inner))
+			continue
+		case inlineStrong:
+			inner.bold = true
+		case inlineEmph:
+			inner.italic = true
+		case inlineStrike:
+			inner.strike = true
+		case inlineLink:
+			inner.link = true
+			w.links = append(w.links, node.URL)
+			content := w.run(node.URL, inner)
+			if len(node.Children) > 0 {
+				content = w.runs(node.Children, inner)
+			}
+			sb.WriteString(fmt.Sprintf(`<w:hyperlink r:id="rId%d">%s</w:hyperlink>`, len(w.links)+1, content))
+			continue
+		}
+		sb.WriteString(w.runs(node.Children, inner))
+	}
+	return sb.String()
+}
+
+// paragraph 生成一个段落，align 为 left、center 等，indent 为左缩进的缇数
+func (w *docxWriter) paragraph(runs, align string, indent int) string {
+	var pPr strings.Builder
+	pPr.WriteString(`<w:spacing w:before="0" w:after="0"/>`)
+	if indent > 0 {
+		pPr.WriteString(fmt.Sprintf(`<w:ind w:left="%d"/>`, indent))
+	}
+	if align != "" {
+		pPr.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, align))
+	}
+	return "<w:p><w:pPr>" + pPr.String() + "</w:pPr>" + runs + "</w:p>"
+}
+
+// listIndent 列表每一级的缩进，单位为缇
+const listIndent = 360
+
+// listParagraph 将列表项转为带缩进的段落，无序列表使用圆点，有序列表按层级编号
+func (w *docxWriter) listParagraph(line listLine, level int, counters *[]int) string {
+	for len(*counters) <= level {
+		*counters = append(*counters, 0)
+	}
+	*counters = (*counters)[:level+1]
+	marker := "• "
+	if line.ordered {
+		(*counters)[level]++
+		marker = fmt.Sprintf("%d. ", (*counters)[level])
+	}
+	return w.paragraph(w.run(marker, runProps{})+w.runs(parseInline(line.text), runProps{}), "left", (level+1)*listIndent)
+}
+
+// numberedParagraphs 生成编号列的段落，与 formatNumberedContent 的编号方式相同
+func (w *docxWriter) numberedParagraphs(content string, startCounter int) ([]string, int) {
+	lines, counter := numberedLines(content, startCounter)
+	var paragraphs []string
+	var counters []int
+	for _, line := range lines {
+		if line.Number == 0 {
+			paragraphs = append(paragraphs, w.listParagraph(line.listLine, line.level, &counters))
+			continue
+		}
+		counters = nil
+		runs := w.run(fmt.Sprintf("%d. ", line.Number), runProps{}) + w.runs(parseInline(line.text), runProps{}) + w.run("；", runProps{})
+		paragraphs = append(paragraphs, w.paragraph(runs, "left", 0))
+	}
+	return paragraphs, counter
+}
+
+// plainParagraphs 生成每行一段的段落，与 markdownToTypst 的分段方式相同
+func (w *docxWriter) plainParagraphs(content, align string) []string {
+	var paragraphs []string
+	var counters []int
+	for _, line := range parseListLines(content) {
+		if line.list {
+			paragraphs = append(paragraphs, w.listParagraph(line, line.level, &counters))
+			continue
+		}
+		counters = nil
+		paragraphs = append(paragraphs, w.paragraph(w.runs(parseInline(line.text), runProps{}), align, 0))
+	}
+	return paragraphs
+}
+
+// inlineParagraph 生成单行的段落
+func (w *docxWriter) inlineParagraph(text string, props runProps, align string) string {
+	return w.paragraph(w.runs(parseInline(text), props), align, 0)
+}
+
+// cellMerge 单元格的纵向合并状态
+type cellMerge int
+
+const (
+	mergeNone     cellMerge = iota
+	mergeRestart            // 合并的第一行
+	mergeContinue           // 被上方单元格合并
+)
+
+// cell 生成一个单元格，span 为横向合并的列数
+func (w *docxWriter) cell(width, span int, merge cellMerge, paragraphs ...string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, width))
+	if span > 1 {
+		sb.WriteString(fmt.Sprintf(`<w:gridSpan w:val="%d"/>`, span))
+	}
+	switch merge {
+	case mergeRestart:
+		sb.WriteString(`<w:vMerge w:val="restart"/>`)
+	case mergeContinue:
+		sb.WriteString(`<w:vMerge/>`)
+	}
+	sb.WriteString(`<w:vAlign w:val="center"/></w:tcPr>`)
+	if len(paragraphs) == 0 {
+		paragraphs = []string{"<w:p/>"}
+	}
+	sb.WriteString(strings.Join(paragraphs, ""))
+	sb.WriteString("</w:tc>")
+	return sb.String()
+}
+
+// spanWidth 返回从第 col 列起 span 列的总宽度
+func spanWidth(widths []int, col, span int) int {
+	total := 0
+	for _, width := range widths[col : col+span] {
+		total += width
+	}
+	return total
+}
+
+// startTable 开始一个带 0.5 磅边框的表格
+func (w *docxWriter) startTable(widths []int) {
+	w.body.WriteString(fmt.Sprintf(`<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:jc w:val="center"/><w:tblBorders>`, spanWidth(widths, 0, len(widths))))
+	for _, side := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+		w.body.WriteString(fmt.Sprintf(`<w:%s w:val="single" w:sz="4" w:space="0" w:color="000000"/>`, side))
+	}
+	w.body.WriteString(`</w:tblBorders><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid>`)
+	for _, width := range widths {
+		w.body.WriteString(fmt.Sprintf(`<w:gridCol w:w="%d"/>`, width))
+	}
+	w.body.WriteString("</w:tblGrid>")
+}
+
+// row 输出一行单元格
+func (w *docxWriter) row(cells ...string) {
+	w.body.WriteString("<w:tr>" + strings.Join(cells, "") + "</w:tr>")
+}
+
+// pageSize 返回页面的宽、高和正文宽度，单位为缇
+func (s Style) pageSize() (int, int, int) {
+	size := paperSizes[s.Paper] // loadStyle 已检查纸张名称
+	width, height := size[0], size[1]
+	if s.Orientation == "landscape" {
+		width, height = height, width
+	}
+	left, _ := lengthTwips(s.Margin.Left)
+	right, _ := lengthTwips(s.Margin.Right)
+	return width, height, width - left - right
+}
+
+// header 输出封面表格，布局与 generateHeader 相同
+func (w *docxWriter) header(fields []HeaderField, textWidth int) {
+	if len(fields) == 0 {
+		return
+	}
+	label, _ := lengthTwips("2.5cm")
+	value := (textWidth - label*headerPairsPerRow) / headerPairsPerRow
+	widths := make([]int, headerPairsPerRow*2)
+	for i := range widths {
+		widths[i] = label
+		if i%2 == 1 {
+			widths[i] = value
+		}
+	}
+
+	w.startTable(widths)
+	for _, row := range headerRows(fields) {
+		var cells []string
+		col := 0
+		for i, field := range row {
+			cells = append(cells, w.cell(widths[col], 1, mergeNone, w.inlineParagraph(field.Key, runProps{bold: true}, "center")))
+			col++
+			span := headerSpan(row, i)
+
+			var paragraphs []string
+			switch {
+			case len(field.Values) > 1:
+				paragraphs, _ = w.numberedParagraphs(strings.Join(field.Values, "\n"), 1)
+			case len(field.Values) == 1 && field.long():
+				paragraphs = []string{w.inlineParagraph(field.Values[0], runProps{}, "left")}
+			case len(field.Values) == 1:
+				paragraphs = []string{w.inlineParagraph(field.Values[0], runProps{}, "center")}
+			}
+			cells = append(cells, w.cell(spanWidth(widths, col, span), span, mergeNone, paragraphs...))
+			col += span
+		}
+		w.row(cells...)
+	}
+	w.body.WriteString("</w:tbl>")
+}
+
+// activityTable 输出一个二级标题下的教学活动表，布局与 generateTypst 相同
+func (w *docxWriter) activityTable(section DocumentSection, layout Layout, widths []int) {
+	bold := runProps{bold: true}
+	w.startTable(widths)
+	for _, table := range section.Tables {
+		// 表格第一行
+		span := len(widths) - 3
+		w.row(
+			w.cell(widths[0], 1, mergeNone, w.inlineParagraph(layout.Labels[0], bold, "center")),
+			w.cell(widths[1], 1, mergeNone, w.inlineParagraph(table.H3Part1, bold, "center")),
+			w.cell(widths[2], 1, mergeNone, w.inlineParagraph(layout.Labels[1], bold, "center")),
+			w.cell(spanWidth(widths, 3, span), span, mergeNone, w.inlineParagraph(table.H3Part2, bold, "center")),
+		)
+
+		// 表格第二行
+		var titles []string
+		for i, title := range layout.headings() {
+			titles = append(titles, w.cell(widths[i], 1, mergeNone, w.inlineParagraph(title, runProps{}, "center")))
+		}
+		w.row(titles...)
+
+		h4Counter := 1
+		grid, _ := activityRows(table, layout)
+		for k, h4 := range table.H4Blocks {
+			if len(h4.H5Blocks) == 0 {
+				continue
+			}
+			rows := grid[k]
+			counters := layout.startCounters()
+			for i, row := range rows {
+				var cells []string
+				if i == 0 {
+					title := w.paragraph(w.run(fmt.Sprintf("%d. ", h4Counter), runProps{})+w.runs(parseInline(h4.Title), runProps{}), "center", 0)
+					merge := mergeNone
+					if len(rows) > 1 {
+						merge = mergeRestart
+					}
+					cells = append(cells, w.cell(widths[0], 1, merge, title))
+				} else {
+					cells = append(cells, w.cell(widths[0], 1, mergeContinue))
+				}
+
+				for col, cell := range row {
+					// 被左侧单元格合并的列由 gridSpan 占据，不单独输出
+					if cell.Colspan == 0 {
+						continue
+					}
+					width := spanWidth(widths, col+1, cell.Colspan)
+					if cell.Rowspan == 0 {
+						cells = append(cells, w.cell(width, cell.Colspan, mergeContinue))
+						continue
+					}
+					merge := mergeNone
+					if cell.Rowspan > 1 {
+						merge = mergeRestart
+					}
+
+					var paragraphs []string
+					switch layout.Columns[col].Kind {
+					case columnNumbered:
+						paragraphs, counters[col] = w.numberedParagraphs(cell.Content, counters[col])
+					case columnParagraphs:
+						paragraphs = w.plainParagraphs(cell.Content, "center")
+					default:
+						paragraphs = []string{w.inlineParagraph(cell.Content, runProps{}, "center")}
+					}
+					cells = append(cells, w.cell(width, cell.Colspan, merge, paragraphs...))
+				}
+				w.row(cells...)
+			}
+			h4Counter++
+		}
+	}
+	w.body.WriteString("</w:tbl>")
+}
+
+// styles 生成默认字体和字号：正文为宋体五号
+func (w *docxWriter) styles() string {
+	font := "SimSun"
+	if len(w.style.Fonts.Body) > 0 {
+		font = xmlText(w.style.Fonts.Body[0])
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles ` + docxNamespaces + `>
+<w:docDefaults><w:rPrDefault><w:rPr>` +
+		fmt.Sprintf(`<w:rFonts w:ascii="%s" w:hAnsi="%s" w:eastAsia="%s" w:cs="%s"/>`, font, font, font, font) +
+		`<w:sz w:val="21"/><w:szCs w:val="21"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+</w:styles>`
+}
+
+// generateDocx 根据解析出的结构体生成 Word 文档
+func generateDocx(doc Document, style Style) ([]byte, error) {
+	w := &docxWriter{style: style}
+	pageWidth, pageHeight, textWidth := style.pageSize()
+
+	w.header(doc.Header, textWidth)
+	widths := columnWidths(style.activityWidths(doc.Layout), textWidth)
+	for _, section := range doc.Sections {
+		// 二级标题：居中，四号字
+		w.body.WriteString(`<w:p><w:pPr><w:spacing w:before="240" w:after="240"/><w:jc w:val="center"/></w:pPr>` + w.runs(parseInline(section.H2Title), runProps{size: 28}) + "</w:p>")
+		if len(section.Tables) > 0 {
+			w.activityTable(section, doc.Layout, widths)
+		}
+	}
+
+	var document strings.Builder
+	document.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	document.WriteString("<w:document " + docxNamespaces + "><w:body>")
+	document.WriteString(w.body.String())
+	// 正文以段落结束，并设置页面
+	document.WriteString("<w:p/><w:sectPr>")
+	orient := ""
+	if style.Orientation == "landscape" {
+		orient = ` w:orient="landscape"`
+	}
+	document.WriteString(fmt.Sprintf(`<w:pgSz w:w="%d" w:h="%d"%s/>`, pageWidth, pageHeight, orient))
+	margins := make([]int, 4)
+	for i, length := range []string{style.Margin.Top, style.Margin.Right, style.Margin.Bottom, style.Margin.Left} {
+		margins[i], _ = lengthTwips(length)
+	}
+	document.WriteString(fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="851" w:footer="992" w:gutter="0"/>`, margins[0], margins[1], margins[2], margins[3]))
+	document.WriteString("</w:sectPr></w:body></w:document>")
+
+	var rels strings.Builder
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	rels.WriteString(`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, link := range w.links {
+		rels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`, i+2, xmlText(link)))
+	}
+	rels.WriteString("</Relationships>")
+
+	// 打包为 zip
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/document.xml", document.String()},
+		{"word/styles.xml", w.styles()},
+		{"word/_rels/document.xml.rels", rels.String()},
+	} {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
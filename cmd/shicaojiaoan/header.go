@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderField 存储文档属性中的一项教案封面信息
+type HeaderField struct {
+	Key    string
+	Values []string // 单行值只有一项，列表或多行文本每行一项
+}
+
+// longHeaderFields 即使只有一行也独占表格一行的字段
+var longHeaderFields = map[string]bool{
+	"教学目标": true,
+	"重难点":  true,
+	"教学重点": true,
+	"教学难点": true,
+}
+
+// headerPairsPerRow 封面表格中每行容纳的短字段数
+const headerPairsPerRow = 3
+
+// long 判断字段是否独占表格一行
+func (f HeaderField) long() bool {
+	return longHeaderFields[f.Key] || len(f.Values) > 1
+}
+
+// splitFrontMatter 从内容中分离 YAML 文档属性，返回属性行和其余行
+func splitFrontMatter(lines []string) ([]string, []string) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return lines[1:i], lines[i+1:]
+		}
+	}
+	return nil, lines
+}
+
+// parseFrontMatter 解析文档属性，支持 “键: 值”、缩进的 “- 列表项” 和 “键: |” 多行文本，
+// 字段按书写顺序返回
+func parseFrontMatter(lines []string) []HeaderField {
+	var fields []HeaderField
+	var current *HeaderField
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// 缩进的行属于上一个字段
+		if current != nil && (line[0] == ' ' || line[0] == '\t') {
+			value := strings.TrimSpace(strings.TrimPrefix(trimmed, "- "))
+			if trimmed == "-" {
+				value = ""
+			}
+			if value = unquote(value); value != "" {
+				current.Values = append(current.Values, value)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			key, value, ok = strings.Cut(trimmed, "：")
+		}
+		if !ok {
+			continue
+		}
+		fields = append(fields, HeaderField{Key: strings.TrimSpace(key)})
+		current = &fields[len(fields)-1]
+
+		value = strings.TrimSpace(value)
+		if value == "|" || value == ">" || value == "|-" || value == ">-" {
+			continue
+		}
+		if value = unquote(value); value != "" {
+			current.Values = append(current.Values, value)
+		}
+	}
+	return fields
+}
+
+// unquote 去除值两侧成对的引号
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// headerValue 将字段值转为 Typst 标记，多项时逐项编号
+func headerValue(field HeaderField) string {
+	if len(field.Values) > 1 {
+		formatted, _ := formatNumberedContent(strings.Join(field.Values, "\n"), 1)
+		return formatted
+	}
+	if len(field.Values) == 1 {
+		return inlineMarkdown(field.Values[0])
+	}
+	return ""
+}
+
+// headerRows 将字段排成封面表格的各行：短字段每行三组，长字段独占一行
+func headerRows(fields []HeaderField) [][]HeaderField {
+	var rows [][]HeaderField
+	var row []HeaderField
+	for _, field := range fields {
+		if !field.long() {
+			row = append(row, field)
+			if len(row) == headerPairsPerRow {
+				rows = append(rows, row)
+				row = nil
+			}
+			continue
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+			row = nil
+		}
+		rows = append(rows, []HeaderField{field})
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// headerSpan 返回一行中第 i 组的值所占的列数，最后一组的值占满剩余的列
+func headerSpan(row []HeaderField, i int) int {
+	if i == len(row)-1 {
+		return (headerPairsPerRow-len(row))*2 + 1
+	}
+	return 1
+}
+
+// generateHeader 将文档属性生成为六列的封面表格
+func generateHeader(fields []HeaderField) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n#table(\n")
+	sb.WriteString("  columns: (auto, 1fr, auto, 1fr, auto, 1fr),\n")
+	sb.WriteString("  stroke: 0.5pt,\n")
+	sb.WriteString("  align: center + horizon,\n")
+	for _, row := range headerRows(fields) {
+		sb.WriteString(" ")
+		for i, field := range row {
+			sb.WriteString(fmt.Sprintf(" [*%s*],", inlineMarkdown(field.Key)))
+			switch span := headerSpan(row, i); {
+			case field.long():
+				sb.WriteString(fmt.Sprintf(" table.cell(colspan: %d, align: left)[%s],", span, headerValue(field)))
+			case span > 1:
+				sb.WriteString(fmt.Sprintf(" table.cell(colspan: %d)[%s],", span, headerValue(field)))
+			default:
+				sb.WriteString(fmt.Sprintf(" [%s],", headerValue(field)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
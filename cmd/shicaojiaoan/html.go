@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlInline 将行内元素转为 HTML
+func htmlInline(nodes []inlineNode) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		switch node.Kind {
+		case inlineText:
+			sb.WriteString(html.EscapeString(node.Text))
+		case inlineStrong:
+			sb.WriteString("<strong>" + htmlInline(node.Children) + "</strong>")
+		case inlineEmph:
+			sb.WriteString("<em>" + htmlInline(node.Children) + "</em>")
+		case inlineStrike:
+			sb.WriteString("<del>" + htmlInline(node.Children) + "</del>")
+		case inlineCode:
+			sb.WriteString("<code>" + html.EscapeString(node.Text) + "</code>")
+		case inlineLink:
+			label := html.EscapeString(node.URL)
+			if len(node.Children) > 0 {
+				label = htmlInline(node.Children)
+			}
+			sb.WriteString(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(node.URL), label))
+		}
+	}
+	return sb.String()
+}
+
+// htmlText 将一行 Markdown 转为 HTML
+func htmlText(text string) string {
+	return htmlInline(parseInline(text))
+}
+
+// htmlListItem 将列表项转为缩进的段落，标记与 Word 输出相同
+func htmlListItem(line listLine, level int, counters *[]int) string {
+	for len(*counters) <= level {
+		*counters = append(*counters, 0)
+	}
+	*counters = (*counters)[:level+1]
+	marker := "•"
+	if line.ordered {
+		(*counters)[level]++
+		marker = fmt.Sprintf("%d.", (*counters)[level])
+	}
+	return fmt.Sprintf(`<p class="li" style="padding-left: %.1fem">%s %s</p>`, float64(level+1)*1.5, marker, htmlText(line.text))
+}
+
+// htmlNumbered 生成编号列的内容，与 formatNumberedContent 的编号方式相同
+func htmlNumbered(content string, startCounter int) (string, int) {
+	lines, counter := numberedLines(content, startCounter)
+	var sb strings.Builder
+	var counters []int
+	for _, line := range lines {
+		if line.Number == 0 {
+			sb.WriteString(htmlListItem(line.listLine, line.level, &counters))
+			continue
+		}
+		counters = nil
+		sb.WriteString(fmt.Sprintf("<p>%d. %s；</p>", line.Number, htmlText(line.text)))
+	}
+	return sb.String(), counter
+}
+
+// htmlParagraphs 生成每行一段的内容，与 markdownToTypst 的分段方式相同
+func htmlParagraphs(content string) string {
+	var sb strings.Builder
+	var counters []int
+	for _, line := range parseListLines(content) {
+		if line.list {
+			sb.WriteString(htmlListItem(line, line.level, &counters))
+			continue
+		}
+		counters = nil
+		sb.WriteString("<p>" + htmlText(line.text) + "</p>")
+	}
+	return sb.String()
+}
+
+// htmlCell 生成一个单元格，rowspan 和 colspan 为 1 时省略
+func htmlCell(content string, rowspan, colspan int, class string) string {
+	var attrs string
+	if rowspan > 1 {
+		attrs += fmt.Sprintf(` rowspan="%d"`, rowspan)
+	}
+	if colspan > 1 {
+		attrs += fmt.Sprintf(` colspan="%d"`, colspan)
+	}
+	if class != "" {
+		attrs += fmt.Sprintf(` class="%s"`, class)
+	}
+	return fmt.Sprintf("<td%s>%s</td>", attrs, content)
+}
+
+// cssFonts 将字体列表转为 CSS font-family
+func cssFonts(fonts []string, fallback string) string {
+	quoted := make([]string, 0, len(fonts)+1)
+	for _, font := range fonts {
+		quoted = append(quoted, fmt.Sprintf("%q", font))
+	}
+	return strings.Join(append(quoted, fallback), ", ")
+}
+
+// cssPageSizes 纸张名称对应的 CSS 页面尺寸，Typst 与 CSS 的名称不尽相同
+var cssPageSizes = map[string]string{
+	"a3":        "A3",
+	"a4":        "A4",
+	"a5":        "A5",
+	"iso-b5":    "B5",
+	"us-letter": "letter",
+}
+
+// htmlStyle 生成与样式对应的 CSS，打印时使用样式中的纸张、方向和页边距
+func htmlStyle(style Style) string {
+	return fmt.Sprintf(`@page { size: %s %s; margin: %s %s %s %s; }
+body { font-family: %s; font-size: 10.5pt; margin: 1em auto; max-width: 26cm; }
+h2 { font-family: %s; font-size: 14pt; font-weight: normal; text-align: center; margin: 1.2em 0; }
+table { border-collapse: collapse; width: 100%%; margin-bottom: 1em; }
+td { border: 0.5pt solid #000; padding: 4pt 5pt; text-align: center; vertical-align: middle; }
+td.left { text-align: left; }
+td.head { font-weight: bold; }
+td p { margin: 0; }
+code { font-family: monospace; }
+strong { font-weight: bold; }
+@media print { body { margin: 0; max-width: none; } }
+`, cssPageSizes[style.Paper], style.Orientation, style.Margin.Top, style.Margin.Right, style.Margin.Bottom, style.Margin.Left,
+		cssFonts(style.Fonts.Body, "serif"), cssFonts(style.Fonts.Body, "serif"))
+}
+
+// htmlColgroup 生成教学活动表的列宽，auto 和 fr 列不设宽度
+func htmlColgroup(columns []string) string {
+	var sb strings.Builder
+	sb.WriteString("<colgroup>")
+	for _, column := range columns {
+		if _, ok := lengthTwips(column); ok {
+			sb.WriteString(fmt.Sprintf(`<col style="width: %s">`, column))
+		} else {
+			sb.WriteString("<col>")
+		}
+	}
+	sb.WriteString("</colgroup>")
+	return sb.String()
+}
+
+// generateHTML 根据解析出的结构体生成独立的 HTML 文件，布局与 generateTypst 相同，
+// script 不为空时插入页面末尾，用于预览时自动刷新
+func generateHTML(doc Document, style Style, title, script string) string {
+	layout := doc.Layout
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"zh\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	sb.WriteString("<style>\n" + htmlStyle(style) + "</style>\n</head>\n<body>\n")
+
+	// 封面表格
+	if len(doc.Header) > 0 {
+		sb.WriteString("<table class=\"header\">\n")
+		for _, row := range headerRows(doc.Header) {
+			sb.WriteString("<tr>")
+			for i, field := range row {
+				sb.WriteString(htmlCell(htmlText(field.Key), 1, 1, "head"))
+				var value, class string
+				switch {
+				case len(field.Values) > 1:
+					value, _ = htmlNumbered(strings.Join(field.Values, "\n"), 1)
+					class = "left"
+				case len(field.Values) == 1:
+					value = htmlText(field.Values[0])
+					if field.long() {
+						class = "left"
+					}
+				}
+				sb.WriteString(htmlCell(value, 1, headerSpan(row, i), class))
+			}
+			sb.WriteString("</tr>\n")
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	for _, section := range doc.Sections {
+		sb.WriteString("<h2>" + htmlText(section.H2Title) + "</h2>\n")
+		if len(section.Tables) == 0 {
+			continue
+		}
+
+		sb.WriteString("<table class=\"activity\">" + htmlColgroup(style.activityWidths(layout)) + "\n")
+		for _, table := range section.Tables {
+			// 表格第一行和第二行
+			sb.WriteString("<tr>" + htmlCell(layout.Labels[0], 1, 1, "head") + htmlCell(htmlText(table.H3Part1), 1, 1, "head") +
+				htmlCell(layout.Labels[1], 1, 1, "head") + htmlCell(htmlText(table.H3Part2), 1, len(layout.Columns)-2, "head") + "</tr>\n")
+			sb.WriteString("<tr>")
+			for _, heading := range layout.headings() {
+				sb.WriteString("<td>" + heading + "</td>")
+			}
+			sb.WriteString("</tr>\n")
+
+			h4Counter := 1
+			grid, _ := activityRows(table, layout)
+			for k, h4 := range table.H4Blocks {
+				if len(h4.H5Blocks) == 0 {
+					continue
+				}
+				rows := grid[k]
+				counters := layout.startCounters()
+				for i, row := range rows {
+					sb.WriteString("<tr>")
+					if i == 0 {
+						sb.WriteString(htmlCell(fmt.Sprintf("%d. %s", h4Counter, htmlText(h4.Title)), len(rows), 1, ""))
+					}
+					for col, cell := range row {
+						if cell.Rowspan == 0 {
+							continue
+						}
+						var content, class string
+						switch layout.Columns[col].Kind {
+						case columnNumbered:
+							content, counters[col] = htmlNumbered(cell.Content, counters[col])
+							if content != "" {
+								class = "left"
+							}
+						case columnParagraphs:
+							content = htmlParagraphs(cell.Content)
+						default:
+							content = htmlText(cell.Content)
+						}
+						sb.WriteString(htmlCell(content, cell.Rowspan, cell.Colspan, class))
+					}
+					sb.WriteString("</tr>\n")
+				}
+				h4Counter++
+			}
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	if script != "" {
+		sb.WriteString("<script>\n" + script + "</script>\n")
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
@@ -0,0 +1,630 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// xmlNode Word 文档 XML 中的一个元素，名称和属性只保留本地名
+type xmlNode struct {
+	Name     string
+	Attrs    map[string]string
+	Children []*xmlNode
+	Text     string
+}
+
+// child 返回第一个指定名称的子元素
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// children 返回所有指定名称的子元素
+func (n *xmlNode) children(name string) []*xmlNode {
+	var nodes []*xmlNode
+	for _, c := range n.Children {
+		if c.Name == name {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// path 按名称逐级查找子元素，任一级不存在时返回空
+func (n *xmlNode) path(names ...string) *xmlNode {
+	for _, name := range names {
+		if n == nil {
+			return nil
+		}
+		n = n.child(name)
+	}
+	return n
+}
+
+// attr 返回属性值，元素不存在时返回空字符串
+func (n *xmlNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	return n.Attrs[name]
+}
+
+// on 判断开关属性是否开启，如 <w:b/> 开启，<w:b w:val="0"/> 关闭
+func (n *xmlNode) on() bool {
+	if n == nil {
+		return false
+	}
+	val := n.attr("val")
+	return val != "0" && val != "false" && val != "none"
+}
+
+// parseXML 将 XML 解析为元素树
+func parseXML(r io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(r)
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local, Attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].Text += string(t)
+		}
+	}
+}
+
+// readDocxPart 读取 docx 中的一个部件，不存在时返回空
+func readDocxPart(archive *zip.ReadCloser, name string) (*xmlNode, error) {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return parseXML(r)
+	}
+	return nil, nil
+}
+
+// docxRun 段落中格式相同的一段文本
+type docxRun struct {
+	text                       string
+	bold, italic, strike, code bool
+	link                       string
+}
+
+// monospaceFonts 视为行内代码的等宽字体
+var monospaceFonts = map[string]bool{
+	"Courier New": true,
+	"Consolas":    true,
+	"Menlo":       true,
+	"Monaco":      true,
+}
+
+// markdownEscaper 转义正文中会被当作 Markdown 格式的字符
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "~", `\~`)
+
+// paragraphRuns 读取段落中的文本段，超链接地址取自文档关系
+func paragraphRuns(p *xmlNode, rels map[string]string) []docxRun {
+	var runs []docxRun
+	var walk func(n *xmlNode, link string)
+	walk = func(n *xmlNode, link string) {
+		for _, c := range n.Children {
+			switch c.Name {
+			case "r":
+				rPr := c.child("rPr")
+				run := docxRun{
+					bold:   rPr.path("b").on(),
+					italic: rPr.path("i").on(),
+					strike: rPr.path("strike").on(),
+					code:   monospaceFonts[rPr.path("rFonts").attr("ascii")],
+					link:   link,
+				}
+				for _, part := range c.Children {
+					switch part.Name {
+					case "t":
+						run.text += part.Text
+					case "tab":
+						run.text += " "
+					case "br", "cr":
+						run.text += " "
+					}
+				}
+				// 合并格式相同的相邻文本段
+				if last := len(runs) - 1; last >= 0 && runs[last].bold == run.bold && runs[last].italic == run.italic && runs[last].strike == run.strike && runs[last].code == run.code && runs[last].link == run.link {
+					runs[last].text += run.text
+				} else if run.text != "" {
+					runs = append(runs, run)
+				}
+			case "hyperlink":
+				walk(c, rels[c.attr("id")])
+			case "ins", "smartTag", "sdt", "sdtContent", "fldSimple":
+				walk(c, link)
+			}
+		}
+	}
+	walk(p, "")
+	return runs
+}
+
+// runsMarkdown 将文本段转为 Markdown 行内格式
+func runsMarkdown(runs []docxRun) string {
+	var sb strings.Builder
+	for _, run := range runs {
+		// 格式标记不能包住首尾的空白
+		trimmed := strings.TrimSpace(run.text)
+		if trimmed == "" {
+			sb.WriteString(run.text)
+			continue
+		}
+		lead := run.text[:strings.Index(run.text, trimmed)]
+		trail := run.text[len(lead)+len(trimmed):]
+
+		text := markdownEscaper.Replace(trimmed)
+		if run.code && !strings.Contains(trimmed, "`") {
+			text = "`" + trimmed + "`"
+		}
+		if run.strike {
+			text = "~~" + text + "~~"
+		}
+		if run.italic {
+			text = "*" + text + "*"
+		}
+		if run.bold {
+			text = "**" + text + "**"
+		}
+		if run.link != "" {
+			if trimmed == run.link {
+				text = run.link
+			} else {
+				text = "[" + text + "](" + run.link + ")"
+			}
+		}
+		sb.WriteString(lead + text + trail)
+	}
+	return sb.String()
+}
+
+// docxParagraph 单元格中的一个段落
+type docxParagraph struct {
+	text   string
+	plain  string // 不含格式标记的文本，用于识别表头
+	list   bool
+	number string // 有序列表项的序号，如 “2.”
+	level  int    // 列表项的层级，从 0 开始
+}
+
+// markdown 返回段落对应的 Markdown 行，列表项按层级缩进
+func (p docxParagraph) markdown(level int) string {
+	if !p.list {
+		return p.text
+	}
+	marker := "- "
+	if p.number != "" {
+		marker = p.number + " "
+	}
+	return strings.Repeat("  ", level) + marker + p.text
+}
+
+// 导入时去除的编号和标记
+var (
+	importNumberRegex = regexp.MustCompile(`^\d+[.、．]\s*`)
+	importBulletRegex = regexp.MustCompile(`^[•·●○■□◆◇▪–-]\s*`)
+)
+
+// cellParagraphs 读取单元格中的段落，识别列表项及其层级：
+// Word 自动编号的段落按编号层级，本程序生成的列表按缩进，每级缩进 listIndent
+func cellParagraphs(tc *xmlNode, rels map[string]string) []docxParagraph {
+	var paragraphs []docxParagraph
+	for _, p := range tc.children("p") {
+		runs := paragraphRuns(p, rels)
+		text := strings.TrimSpace(runsMarkdown(runs))
+		if text == "" {
+			continue
+		}
+		para := docxParagraph{text: text}
+		for _, run := range runs {
+			para.plain += run.text
+		}
+		para.plain = strings.TrimSpace(para.plain)
+
+		pPr := p.child("pPr")
+		left, _ := strconv.Atoi(pPr.path("ind").attr("left"))
+		bullet := importBulletRegex.FindString(text)
+		switch numPr := pPr.path("numPr"); {
+		case numPr != nil:
+			para.list = true
+			para.level, _ = strconv.Atoi(numPr.path("ilvl").attr("val"))
+		case left >= listIndent:
+			para.list = true
+			para.level = left/listIndent - 1
+			if number := importNumberRegex.FindString(text); number != "" {
+				para.number = strings.TrimRight(strings.TrimSpace(number), ".、．") + "."
+				para.text = strings.TrimPrefix(text, number)
+			} else {
+				para.text = strings.TrimPrefix(text, bullet)
+			}
+		case bullet != "":
+			para.list = true
+			para.text = strings.TrimPrefix(text, bullet)
+		}
+		paragraphs = append(paragraphs, para)
+	}
+	return paragraphs
+}
+
+// gridCell 按表格网格展开后的单元格
+type gridCell struct {
+	paragraphs []docxParagraph
+	merged     bool // 被上方单元格纵向合并
+	covered    bool // 被左侧单元格横向合并
+}
+
+// text 返回单元格中所有段落的文本
+func (c gridCell) text() string {
+	var lines []string
+	for _, p := range c.paragraphs {
+		lines = append(lines, p.text)
+	}
+	return strings.Join(lines, "")
+}
+
+// plain 返回单元格中不含格式标记的文本
+func (c gridCell) plain() string {
+	var lines []string
+	for _, p := range c.paragraphs {
+		lines = append(lines, p.plain)
+	}
+	return strings.Join(lines, "")
+}
+
+// tableGrid 将表格展开为网格，横向合并的单元格占据多列
+func tableGrid(tbl *xmlNode, rels map[string]string) [][]gridCell {
+	var grid [][]gridCell
+	for _, tr := range tbl.children("tr") {
+		var row []gridCell
+		for _, tc := range tr.children("tc") {
+			tcPr := tc.child("tcPr")
+			span := 1
+			if gridSpan := tcPr.path("gridSpan"); gridSpan != nil {
+				span, _ = strconv.Atoi(gridSpan.attr("val"))
+				span = max(span, 1)
+			}
+			vMerge := tcPr.path("vMerge")
+			cell := gridCell{
+				paragraphs: cellParagraphs(tc, rels),
+				merged:     vMerge != nil && vMerge.attr("val") != "restart",
+			}
+			row = append(row, cell)
+			for i := 1; i < span; i++ {
+				row = append(row, gridCell{covered: true, merged: cell.merged})
+			}
+		}
+		grid = append(grid, row)
+	}
+	return grid
+}
+
+// cellAt 返回网格行中的第 col 列，不存在时返回空单元格
+func cellAt(row []gridCell, col int) gridCell {
+	if col < len(row) {
+		return row[col]
+	}
+	return gridCell{}
+}
+
+// importField 将封面表格中的一对标签和值转为文档属性
+func importField(label, value gridCell) HeaderField {
+	field := HeaderField{Key: strings.TrimSuffix(strings.TrimSuffix(label.plain(), "："), ":")}
+	for _, p := range value.paragraphs {
+		text := strings.TrimSuffix(importNumberRegex.ReplaceAllString(p.text, ""), "；")
+		if len(value.paragraphs) == 1 {
+			text = p.text
+		}
+		field.Values = append(field.Values, text)
+	}
+	return field
+}
+
+// importHeader 读取封面表格：每行依次为标签和值
+func importHeader(grid [][]gridCell) []HeaderField {
+	var fields []HeaderField
+	for _, row := range grid {
+		var cells []gridCell
+		for _, cell := range row {
+			if !cell.covered {
+				cells = append(cells, cell)
+			}
+		}
+		for i := 0; i+1 < len(cells); i += 2 {
+			if field := importField(cells[i], cells[i+1]); field.Key != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// numberedMarkdown 将编号列的段落转为 Markdown：去除序号和句末的分号，列表项嵌套在上一个编号行之下
+func numberedMarkdown(paragraphs []docxParagraph) string {
+	var lines []string
+	numbered := false
+	for _, p := range paragraphs {
+		if p.list {
+			level := p.level
+			if numbered {
+				level = max(level-1, 0)
+			}
+			lines = append(lines, p.markdown(level))
+			continue
+		}
+		numbered = true
+		lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(importNumberRegex.ReplaceAllString(p.text, ""), "；"), ";"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainMarkdown 将每行一段的段落转为 Markdown
+func plainMarkdown(paragraphs []docxParagraph) string {
+	var lines []string
+	for _, p := range paragraphs {
+		lines = append(lines, p.markdown(p.level))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// importDocx 读取按 generateDocx 的布局排版的 Word 教案，转为 parseMarkdown 所需的结构：
+// 教学活动表之前的其他表格作为封面信息，表格前的段落作为二级标题，纵向合并的单元格写为“同上”，横向合并的写为“同左”
+func importDocx(file string) (Document, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return Document{}, err
+	}
+	defer archive.Close()
+
+	document, err := readDocxPart(archive, "word/document.xml")
+	if err != nil {
+		return Document{}, err
+	}
+	body := document.path("document", "body")
+	if body == nil {
+		return Document{}, fmt.Errorf("%s is not a Word document", file)
+	}
+
+	// 超链接地址
+	rels := make(map[string]string)
+	if relsNode, err := readDocxPart(archive, "word/_rels/document.xml.rels"); err == nil && relsNode != nil {
+		for _, rel := range relsNode.path("Relationships").children("Relationship") {
+			if path.Base(rel.attr("Type")) == "hyperlink" {
+				rels[rel.attr("Id")] = rel.attr("Target")
+			}
+		}
+	}
+
+	var doc Document
+	var heading string
+	for _, node := range body.Children {
+		switch node.Name {
+		case "p":
+			if text := strings.TrimSpace(runsMarkdown(paragraphRuns(node, rels))); text != "" {
+				heading = text
+			}
+		case "tbl":
+			grid := tableGrid(node, rels)
+			if len(grid) == 0 {
+				continue
+			}
+			layout, ok := layoutByLabel(cellAt(grid[0], 0).plain())
+			if !ok {
+				if len(doc.Sections) == 0 {
+					doc.Header = append(doc.Header, importHeader(grid)...)
+				}
+				continue
+			}
+			if len(doc.Sections) == 0 {
+				doc.Layout, doc.LayoutName = layout, layout.Name
+			}
+			if heading == "" {
+				heading = "教学活动设计"
+			}
+			doc.Sections = append(doc.Sections, importActivityTable(grid, heading, layout))
+			heading = ""
+		}
+	}
+	if len(doc.Sections) == 0 {
+		return Document{}, fmt.Errorf("no activity table found in %s", file)
+	}
+	return doc, nil
+}
+
+// layoutByLabel 按表格第一个单元格的标签识别教案类型
+func layoutByLabel(label string) (Layout, bool) {
+	for _, name := range layoutNames() {
+		if layouts[name].Labels[0] == label {
+			return layouts[name], true
+		}
+	}
+	return Layout{}, false
+}
+
+// importActivityTable 按教案类型的各列读取一个教学活动表
+func importActivityTable(grid [][]gridCell, heading string, layout Layout) DocumentSection {
+	section := DocumentSection{H2Title: heading}
+	var table *Table
+	var h4 *H4Block
+	for _, row := range grid {
+		first := cellAt(row, 0)
+		switch first.plain() {
+		case layout.Labels[0]:
+			// 表头整行加粗，取不含格式的文本；第四列起横向合并，取第一个单元格
+			section.Tables = append(section.Tables, Table{H3Part1: cellAt(row, 1).plain(), H3Part2: cellAt(row, 3).plain()})
+			table = &section.Tables[len(section.Tables)-1]
+			h4 = nil
+			continue
+		case layout.Activity:
+			continue
+		}
+		if table == nil {
+			continue
+		}
+
+		// 第一列未被合并时开始新的教学活动
+		if !first.merged || h4 == nil {
+			title := importNumberRegex.ReplaceAllString(first.text(), "")
+			table.H4Blocks = append(table.H4Blocks, H4Block{Title: title})
+			h4 = &table.H4Blocks[len(table.H4Blocks)-1]
+		}
+
+		h5 := H5Block{Content: make([]string, layout.contentColumns())}
+		for i, column := range layout.Columns {
+			cell := cellAt(row, i+1)
+			var content string
+			switch {
+			case cell.merged:
+				content = mergeUp
+			case cell.covered:
+				content = mergeLeft
+			case column.Kind == columnNumbered:
+				content = numberedMarkdown(cell.paragraphs)
+			case column.Kind == columnParagraphs:
+				content = plainMarkdown(cell.paragraphs)
+			default:
+				content = cell.text()
+			}
+			if column.Source == sourceTitle {
+				h5.Title = content
+			} else {
+				h5.Content[column.Source] = content
+			}
+		}
+		h4.H5Blocks = append(h4.H5Blocks, h5)
+	}
+	return section
+}
+
+// frontMatterMarkdown 将封面信息写为文档属性
+func frontMatterMarkdown(fields []HeaderField) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, field := range fields {
+		switch {
+		case len(field.Values) > 1 || (field.long() && len(field.Values) == 0):
+			sb.WriteString(field.Key + ":\n")
+			for _, value := range field.Values {
+				sb.WriteString("  - " + value + "\n")
+			}
+		case len(field.Values) == 1:
+			sb.WriteString(field.Key + ": " + field.Values[0] + "\n")
+		default:
+			sb.WriteString(field.Key + ":\n")
+		}
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// generateMarkdown 将教案写为 parseMarkdown 能读取的 Markdown，空单元格写为 emptyCell，
+// 不是默认类型的教案在文档属性中写出教案类型
+func generateMarkdown(doc Document) string {
+	var sb strings.Builder
+	header := doc.Header
+	if doc.LayoutName != "" && doc.LayoutName != layouts[defaultLayout].Name {
+		header = append([]HeaderField{{Key: layoutKey, Values: []string{doc.LayoutName}}}, header...)
+	}
+	if len(header) > 0 {
+		sb.WriteString(frontMatterMarkdown(header))
+	}
+	for _, section := range doc.Sections {
+		sb.WriteString("## " + section.H2Title + "\n\n")
+		for _, table := range section.Tables {
+			sb.WriteString("### " + table.H3Part1 + "——" + table.H3Part2 + "\n\n")
+			for _, h4 := range table.H4Blocks {
+				sb.WriteString("#### " + h4.Title + "\n\n")
+				for _, h5 := range h4.H5Blocks {
+					title := h5.Title
+					if title == "" {
+						title = emptyCell
+					}
+					sb.WriteString("##### " + title + "\n\n")
+
+					// 末尾的空单元格可以省略
+					content := h5.Content
+					for len(content) > 0 && content[len(content)-1] == "" {
+						content = content[:len(content)-1]
+					}
+					for _, block := range content {
+						if block == "" {
+							block = emptyCell
+						}
+						sb.WriteString(block + "\n\n")
+					}
+				}
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// runImport 将 Word 教案转为同名的 Markdown 文件，不覆盖已有文件
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	output := fs.String("o", "", "输出文件，默认为与输入文件同名的 .md 文件")
+	fs.BoolVar(&verbose, "v", false, "显示详细输出信息")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatal("usage: shicaojiaoan import [-o 输出文件] 文件.docx")
+	}
+	inputFile := fs.Arg(0)
+	outputFile := *output
+	if outputFile == "" {
+		outputFile = strings.TrimSuffix(inputFile, filepath.Ext(inputFile)) + ".md"
+	}
+	if _, err := os.Stat(outputFile); err == nil {
+		log.Fatalf("%s already exists", outputFile)
+	}
+
+	doc, err := importDocx(inputFile)
+	if err != nil {
+		log.Fatalf("failed to import %s: %v", inputFile, err)
+	}
+	if err := ioutil.WriteFile(outputFile, []byte(generateMarkdown(doc)), 0644); err != nil {
+		log.Fatalf("failed to write file: %v", err)
+	}
+	if verbose {
+		log.Printf("imported %s to %s", inputFile, outputFile)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportDocxRoundTrip(t *testing.T) {
+	source := `---
+课程名称: 机械制图
+授课班级: 24 机电 1 班
+教学目标: 识读三视图
+---
+
+## 教学活动设计——任务一
+
+### 识读零件图——绘制轴类零件
+
+#### 导入
+
+##### 0.5H
+
+展示零件实物
+
+观察零件
+
+- 提问
+- 讲解
+
+讲授法
+
+#### 练习
+
+##### 1.5H
+
+绘制主视图
+
+同上
+
+**巡回指导**
+
+同左
+`
+	style, err := loadStyle("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := parseMarkdown(source)
+	if err := applyLayout(&doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := generateDocx(doc, style)
+	if err != nil {
+		t.Fatalf("generateDocx() error = %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "教案.docx")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := importDocx(file)
+	if err != nil {
+		t.Fatalf("importDocx() error = %v", err)
+	}
+	if got := generateMarkdown(imported); got != source {
+		t.Errorf("导出再导入后的 Markdown 与原文不同:\n%s\nwant:\n%s", got, source)
+	}
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// typstSpecial 在 Typst 标记中始终具有特殊含义、需要转义的字符
+const typstSpecial = "\\#$*_`@<>[]~"
+
+// listItemRegex 匹配 Markdown 列表项，分组依次为缩进、列表标记和正文
+var listItemRegex = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])[ \t]+(.*)$`)
+
+// bareURLRegex 匹配正文中直接书写的网址
+var bareURLRegex = regexp.MustCompile(`^https?://[^\s<>\[\]()（）]+`)
+
+// escapeTypst 转义 Typst 标记中的特殊字符，atStart 表示文本位于行首
+func escapeTypst(text string, atStart bool) string {
+	var sb strings.Builder
+	runes := []rune(text)
+	digits := false // 行首已出现数字
+	for i, r := range runes {
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		escape := strings.ContainsRune(typstSpecial, r)
+		switch r {
+		case '-':
+			// 行首的列表标记，以及 --、-?、-> 等简写
+			escape = atStart || next == '-' || next == '?' || next == '>'
+		case '/':
+			// 行首的术语列表，以及注释 // 和 /*
+			escape = atStart || next == '/' || next == '*'
+		case '+', '=':
+			// 行首的编号列表和标题
+			escape = atStart
+		case '.':
+			// 行首的 “1.” 会被识别为编号列表
+			escape = digits
+		}
+		if escape {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+
+		switch {
+		case atStart && unicode.IsDigit(r), digits && unicode.IsDigit(r):
+			atStart, digits = false, true
+		case atStart && unicode.IsSpace(r):
+		default:
+			atStart, digits = false, false
+		}
+	}
+	return sb.String()
+}
+
+// typstString 将文本转为 Typst 字符串字面量
+func typstString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// inlineKind 行内元素的类型
+type inlineKind int
+
+const (
+	inlineText inlineKind = iota
+	inlineStrong
+	inlineEmph
+	inlineStrike
+	inlineCode
+	inlineLink
+)
+
+// inlineNode 一行 Markdown 中的行内元素
+type inlineNode struct {
+	Kind     inlineKind
+	Text     string       // 普通文本和行内代码的内容
+	URL      string       // 链接地址
+	Children []inlineNode // 粗体、斜体、删除线和链接文本的内容，直接书写的网址没有链接文本
+}
+
+// parseInline 解析一行 Markdown 的行内格式：粗体、斜体、删除线、行内代码、链接和网址
+func parseInline(text string) []inlineNode {
+	var nodes []inlineNode
+	var plain strings.Builder
+
+	// add 输出累积的普通文本后添加元素
+	add := func(node inlineNode) {
+		if plain.Len() > 0 {
+			nodes = append(nodes, inlineNode{Kind: inlineText, Text: plain.String()})
+			plain.Reset()
+		}
+		nodes = append(nodes, node)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_{}[]()#+-.!~<>|", rune(rest[1])):
+			// Markdown 转义字符按原样输出
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			if end := strings.Index(rest[ticks:], rest[:ticks]); end >= 0 {
+				add(inlineNode{Kind: inlineCode, Text: strings.TrimSpace(rest[ticks : ticks+end])})
+				i += ticks + end + ticks
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**"), strings.HasPrefix(rest, "__"):
+			if inner, n, ok := delimited(rest, rest[:2]); ok {
+				add(inlineNode{Kind: inlineStrong, Children: parseInline(inner)})
+				i += n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if inner, n, ok := delimited(rest, "~~"); ok {
+				add(inlineNode{Kind: inlineStrike, Children: parseInline(inner)})
+				i += n
+				continue
+			}
+
+		case rest[0] == '*', rest[0] == '_':
+			// 单词中间的下划线（如 snake_case）不视为斜体
+			if rest[0] == '_' && lastRuneIsWord(text[:i]) {
+				break
+			}
+			if inner, n, ok := delimited(rest, rest[:1]); ok {
+				add(inlineNode{Kind: inlineEmph, Children: parseInline(inner)})
+				i += n
+				continue
+			}
+
+		case rest[0] == '!' && strings.HasPrefix(rest[1:], "["):
+			// 表格中不插入图片，只保留替代文本
+			if label, _, n, ok := markdownLink(rest[1:]); ok {
+				plain.WriteString(label)
+				i += 1 + n
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, url, n, ok := markdownLink(rest); ok {
+				add(inlineNode{Kind: inlineLink, URL: url, Children: parseInline(label)})
+				i += n
+				continue
+			}
+
+		case rest[0] == '<':
+			if end := strings.IndexByte(rest, '>'); end > 0 && bareURLRegex.MatchString(rest[1:end]) {
+				add(inlineNode{Kind: inlineLink, URL: rest[1:end]})
+				i += end + 1
+				continue
+			}
+
+		case rest[0] == 'h':
+			if url := bareURLRegex.FindString(rest); url != "" && (i == 0 || !isWordByte(text[i-1])) {
+				add(inlineNode{Kind: inlineLink, URL: url})
+				i += len(url)
+				continue
+			}
+		}
+
+		// 普通字符，按完整的 UTF-8 字符累积
+		_, size := firstRune(rest)
+		plain.WriteString(rest[:size])
+		i += size
+	}
+	if plain.Len() > 0 {
+		nodes = append(nodes, inlineNode{Kind: inlineText, Text: plain.String()})
+	}
+	return nodes
+}
+
+// typstInline 将行内元素转为 Typst 标记，普通文本按需转义
+func typstInline(nodes []inlineNode) string {
+	var sb strings.Builder
+	atStart := true
+	for i, node := range nodes {
+		var expr string
+		switch node.Kind {
+		case inlineText:
+			escaped := escapeTypst(node.Text, atStart)
+			sb.WriteString(escaped)
+			if strings.TrimSpace(escaped) != "" {
+				atStart = false
+			}
+			continue
+		case inlineStrong:
+			expr = "#strong[" + typstInline(node.Children) + "]"
+		case inlineEmph:
+			expr = "#emph[" + typstInline(node.Children) + "]"
+		case inlineStrike:
+			expr = "#strike[" + typstInline(node.Children) + "]"
+		case inlineCode:
+			expr = "#raw(" + typstString(node.Text) + ")"
+		case inlineLink:
+			expr = "#link(" + typstString(node.URL) + ")"
+			if len(node.Children) > 0 {
+				expr += "[" + typstInline(node.Children) + "]"
+			}
+		}
+		sb.WriteString(expr)
+		// 后面紧跟 ( 或 . 时以分号结束表达式，避免被当作调用的一部分
+		if i+1 < len(nodes) && nodes[i+1].Kind == inlineText && strings.IndexAny(nodes[i+1].Text, "(.") == 0 {
+			sb.WriteString(";")
+		}
+		atStart = false
+	}
+	return sb.String()
+}
+
+// inlineMarkdown 将一行 Markdown 行内格式转为 Typst 标记
+func inlineMarkdown(text string) string {
+	return typstInline(parseInline(text))
+}
+
+// delimited 查找以 delim 开始并结束的片段，返回其中的内容和片段长度。
+// 连续的标记字符视为一组，使 *a **b** c* 和 ***x*** 能正确嵌套
+func delimited(text, delim string) (string, int, bool) {
+	body := text[len(delim):]
+	if r, _ := firstRune(body); body == "" || unicode.IsSpace(r) {
+		return "", 0, false
+	}
+	for from := 0; from < len(body); {
+		start := strings.IndexByte(body[from:], delim[0])
+		if start < 0 {
+			return "", 0, false
+		}
+		start += from
+		stop := start
+		for stop < len(body) && body[stop] == delim[0] {
+			stop++
+		}
+		from = stop
+
+		run := stop - start
+		if run < len(delim) || (len(delim) == 1 && run == 2) {
+			continue
+		}
+		end := stop - len(delim)
+		if r, _ := utf8.DecodeLastRuneInString(body[:end]); end == 0 || unicode.IsSpace(r) {
+			continue
+		}
+		// 单词中间的下划线不是结束标记
+		if delim[0] == '_' && isWordRune(body[stop:]) {
+			continue
+		}
+		return body[:end], len(delim)*2 + end, true
+	}
+	return "", 0, false
+}
+
+// markdownLink 解析 [文本](地址)，返回文本、地址和所占长度
+func markdownLink(text string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if !strings.HasPrefix(text[i+1:], "(") {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(text[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			url := strings.TrimSpace(text[i+2 : i+2+end])
+			// 去除链接标题，如 (https://example.com "标题")
+			if space := strings.IndexAny(url, " \t"); space >= 0 {
+				url = url[:space]
+			}
+			return text[1:i], strings.Trim(url, "<>"), i + 3 + end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// isWordByte 判断字节是否为 ASCII 字母或数字
+func isWordByte(b byte) bool {
+	return b < 0x80 && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
+
+// isWordRune 判断文本的第一个字符是否为字母、数字或汉字
+func isWordRune(text string) bool {
+	r, _ := firstRune(text)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lastRuneIsWord 判断文本的最后一个字符是否为字母、数字或汉字
+func lastRuneIsWord(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return text != "" && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// firstRune 返回字符串的第一个字符及其字节数
+func firstRune(text string) (rune, int) {
+	return utf8.DecodeRuneInString(text)
+}
+
+// listLine 单元格中的一行，列表项记录其层级
+type listLine struct {
+	text    string
+	list    bool
+	ordered bool
+	level   int
+}
+
+// parseListLines 识别单元格中的列表项，并根据缩进计算嵌套层级
+func parseListLines(content string) []listLine {
+	var lines []listLine
+	var indents []int // 各层级列表项的缩进宽度
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// 不缩进的 “1.” 是正文中的编号，不视为列表
+		match := listItemRegex.FindStringSubmatch(line)
+		if match != nil && match[1] == "" && match[2][0] >= '0' && match[2][0] <= '9' {
+			match = nil
+		}
+		if match == nil {
+			indents = nil
+			lines = append(lines, listLine{text: strings.TrimSpace(line)})
+			continue
+		}
+		indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+		for len(indents) > 0 && indents[len(indents)-1] > indent {
+			indents = indents[:len(indents)-1]
+		}
+		if len(indents) == 0 || indents[len(indents)-1] < indent {
+			indents = append(indents, indent)
+		}
+		marker := match[2]
+		lines = append(lines, listLine{
+			text:    match[3],
+			list:    true,
+			ordered: marker != "-" && marker != "*" && marker != "+",
+			level:   len(indents) - 1,
+		})
+	}
+	return lines
+}
+
+// typstListItem 输出指定层级的 Typst 列表项
+func typstListItem(line listLine, level int) string {
+	marker := "- "
+	if line.ordered {
+		marker = "+ "
+	}
+	return strings.Repeat("  ", level) + marker + inlineMarkdown(line.text)
+}
+
+// markdownToTypst 将单元格中的多行 Markdown 转为 Typst 标记，
+// 普通行之间以空行分段，列表项按缩进嵌套
+func markdownToTypst(content string) string {
+	var out []string
+	for i, line := range parseListLines(content) {
+		if line.list {
+			out = append(out, typstListItem(line, line.level))
+			continue
+		}
+		if i > 0 {
+			out = append(out, "")
+		}
+		out = append(out, inlineMarkdown(line.text))
+	}
+	return strings.Join(out, "\n")
+}
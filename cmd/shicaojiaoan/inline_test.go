@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestEscapeTypst(t *testing.T) {
+	tests := []struct {
+		text    string
+		atStart bool
+		want    string
+	}{
+		{"/ 术语: 说明", true, `\/ 术语: 说明`},
+		{"输入/输出", true, "输入/输出"},
+		{"a // b /* c", false, `a \// b \/\* c`},
+		{"- 项目", true, `\- 项目`},
+		{"+ 项目", true, `\+ 项目`},
+		{"= 标题", true, `\= 标题`},
+		{"1. 编号", true, `1\. 编号`},
+		{"a-b = c+d", false, "a-b = c+d"},
+		{"#标签 *强调* $5", false, `\#标签 \*强调\* \$5`},
+	}
+	for _, tt := range tests {
+		if got := escapeTypst(tt.text, tt.atStart); got != tt.want {
+			t.Errorf("escapeTypst(%q, %v) = %q, want %q", tt.text, tt.atStart, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// columnKind 单元格内容的排版方式
+type columnKind int
+
+const (
+	columnNumbered   columnKind = iota // 每行编号，列表项嵌套在编号行之下，靠左对齐
+	columnParagraphs                   // 每行单独成段
+	columnHours                        // 取自五级标题的课时分配
+)
+
+// sourceTitle 表示单元格取自五级标题本身，而不是其下的某一段
+const sourceTitle = -1
+
+// LayoutColumn 教学活动表中由五级标题填写的一列
+type LayoutColumn struct {
+	Name   string
+	Kind   columnKind
+	Source int // 填入该列的是五级标题下的第几段（从 0 开始），sourceTitle 表示五级标题
+}
+
+// Layout 教案类型：教学活动表的表头、各列及五级标题内容与单元格的对应关系，
+// 对应关系由各列的 Source 给出
+type Layout struct {
+	Name     string    // 中文名称，写在文档属性“教案类型”中
+	Labels   [2]string // 表格第一行中三级标题两部分前的标签
+	Activity string    // 第一列的列名，填写四级标题
+	Columns  []LayoutColumn
+	Widths   []string // 默认列宽，包括第一列
+	Template string   // 空白模板
+}
+
+// layoutKey 文档属性中选择教案类型的字段，不显示在封面表格中
+const layoutKey = "教案类型"
+
+// defaultLayout 未指定教案类型时使用的类型
+const defaultLayout = "practical"
+
+// layouts 内置的教案类型
+var layouts = map[string]Layout{
+	"practical": {
+		Name:     "实操",
+		Labels:   [2]string{"学习环节", "学习单元"},
+		Activity: "教学活动",
+		Columns: []LayoutColumn{
+			{"学习内容", columnNumbered, 0},
+			{"学生活动", columnNumbered, 1},
+			{"教师活动", columnNumbered, 2},
+			{"教学方法与手段", columnParagraphs, 3},
+			{"课时分配", columnHours, sourceTitle},
+		},
+		Widths:   []string{"2.3cm", "4.2cm", "auto", "auto", "2.2cm", "1.1cm"},
+		Template: templateMd,
+	},
+	"theory": {
+		Name:     "理论",
+		Labels:   [2]string{"授课章节", "课题"},
+		Activity: "教学环节",
+		Columns: []LayoutColumn{
+			{"教学内容", columnNumbered, 0},
+			{"教师活动", columnNumbered, 1},
+			{"学生活动", columnNumbered, 2},
+			{"设计意图", columnParagraphs, 3},
+			{"时间分配", columnHours, sourceTitle},
+		},
+		Widths:   []string{"2.3cm", "5cm", "auto", "auto", "3cm", "1.1cm"},
+		Template: theoryTemplateMd,
+	},
+	"integrated": {
+		Name:     "一体化",
+		Labels:   [2]string{"学习任务", "学习活动"},
+		Activity: "工作环节",
+		Columns: []LayoutColumn{
+			{"工作内容", columnNumbered, 0},
+			{"学生活动", columnNumbered, 1},
+			{"教师活动", columnNumbered, 2},
+			{"评价要点", columnNumbered, 3},
+			{"教学资源", columnParagraphs, 4},
+			{"课时", columnHours, sourceTitle},
+		},
+		Widths:   []string{"2.3cm", "3.6cm", "auto", "auto", "3cm", "2.4cm", "1.1cm"},
+		Template: integratedTemplateMd,
+	},
+}
+
+const theoryTemplateMd = `---
+教案类型: 理论
+课程名称:
+授课班级:
+授课教师:
+授课日期:
+课时:
+教学目标:
+  - 
+重难点:
+  - 
+---
+
+## 教学过程——第一课时
+
+### 章节名称——课题名称
+
+#### 导入新课
+
+##### 5min
+
+教学内容
+
+教师活动
+
+学生活动
+
+设计意图
+`
+
+const integratedTemplateMd = `---
+教案类型: 一体化
+课程名称:
+授课班级:
+授课教师:
+授课日期:
+课时:
+教学目标:
+  - 
+重难点:
+  - 
+---
+
+## 教学活动设计——任务一
+
+### 学习任务名称——学习活动名称
+
+#### 工作环节名称
+
+##### 1H
+
+工作内容
+
+学生活动
+
+教师活动
+
+评价要点
+
+教学资源
+`
+
+// layoutNames 返回所有内置教案类型的名称
+func layoutNames() []string {
+	var names []string
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findLayout 按英文名称或中文名称查找教案类型，名称为空时返回默认类型
+func findLayout(name string) (Layout, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return layouts[defaultLayout], true
+	}
+	if layout, ok := layouts[strings.ToLower(name)]; ok {
+		return layout, true
+	}
+	for _, layout := range layouts {
+		if layout.Name == name {
+			return layout, true
+		}
+	}
+	return layouts[defaultLayout], false
+}
+
+// unknownLayout 返回未知教案类型的错误
+func unknownLayout(name string) error {
+	return fmt.Errorf("unknown layout %q (built-in layouts: %s)", name, strings.Join(layoutNames(), ", "))
+}
+
+// applyLayout 按命令行指定的教案类型设置文档的教案类型，未指定时使用文档属性中声明的类型
+func applyLayout(doc *Document, override string) error {
+	name := doc.LayoutName
+	if override != "" {
+		name = override
+	}
+	layout, ok := findLayout(name)
+	if !ok {
+		return unknownLayout(name)
+	}
+	doc.Layout = layout
+	return nil
+}
+
+// splitLayoutField 从文档属性中取出教案类型字段，返回其余字段和类型名称
+func splitLayoutField(fields []HeaderField) ([]HeaderField, string) {
+	var rest []HeaderField
+	var name string
+	for _, field := range fields {
+		if field.Key == layoutKey {
+			if len(field.Values) > 0 {
+				name = field.Values[0]
+			}
+			continue
+		}
+		rest = append(rest, field)
+	}
+	return rest, name
+}
+
+// contentColumns 返回五级标题下应有的段数，即各列取用的最大段序号加一
+func (l Layout) contentColumns() int {
+	n := 0
+	for _, column := range l.Columns {
+		n = max(n, column.Source+1)
+	}
+	return n
+}
+
+// hoursColumn 返回课时列的位置
+func (l Layout) hoursColumn() int {
+	for i, column := range l.Columns {
+		if column.Kind == columnHours {
+			return i
+		}
+	}
+	return len(l.Columns) - 1
+}
+
+// cells 将五级标题及其内容按各列的 Source 排列
+func (l Layout) cells(h5 H5Block) []string {
+	cells := make([]string, len(l.Columns))
+	for i, column := range l.Columns {
+		if column.Source == sourceTitle {
+			cells[i] = h5.Title
+		} else {
+			cells[i] = getContentLine(h5.Content, column.Source)
+		}
+	}
+	return cells
+}
+
+// startCounters 返回各列的起始序号，每个四级标题重新编号
+func (l Layout) startCounters() []int {
+	counters := make([]int, len(l.Columns))
+	for col := range counters {
+		counters[col] = 1
+	}
+	return counters
+}
+
+// headings 返回表格第二行的各列标题
+func (l Layout) headings() []string {
+	headings := []string{l.Activity}
+	for _, column := range l.Columns {
+		headings = append(headings, column.Name)
+	}
+	return headings
+}
+
+// activityWidths 返回教学活动表的列宽：样式中的列宽与教案类型的列数一致时使用样式，否则使用教案类型的默认列宽
+func (s Style) activityWidths(layout Layout) []string {
+	if len(s.Columns) == len(layout.Widths) {
+		return s.Columns
+	}
+	return layout.Widths
+}
+
+// checkColumns 检查样式中的列宽与教案类型的列数是否一致
+func (s Style) checkColumns(layout Layout) error {
+	if len(s.Columns) > 0 && len(s.Columns) != len(layout.Widths) {
+		return fmt.Errorf("style lists %d column widths but the %s layout has %d columns", len(s.Columns), layout.Name, len(layout.Widths))
+	}
+	return nil
+}
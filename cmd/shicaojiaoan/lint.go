@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lintIssue 教案中的一个结构问题
+type lintIssue struct {
+	Line    int
+	Message string
+}
+
+// hoursRegex 课时分配的写法，如 1H、0.5h、2 课时、2学时、2，以及按分钟计的 5min、5分钟
+var hoursRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([Hh]|课时|学时|min|分钟)?$`)
+
+// classMinutes 一课时的分钟数
+const classMinutes = 45
+
+// totalHoursKeys 文档属性中声明总课时的字段
+var totalHoursKeys = []string{"课时", "总课时", "学时"}
+
+// parseHours 解析课时分配，无法解析时返回 false
+func parseHours(text string) (float64, bool) {
+	match := hoursRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0, false
+	}
+	hours, err := strconv.ParseFloat(match[1], 64)
+	if match[2] == "min" || match[2] == "分钟" {
+		hours /= classMinutes
+	}
+	return hours, err == nil
+}
+
+// formatHours 将课时写为 1H、1.5H 的形式，保留两位小数
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*100)/100, 'f', -1, 64) + "H"
+}
+
+// hourTotal 一个表格或内容区域的课时合计
+type hourTotal struct {
+	Name  string
+	Hours float64
+}
+
+// lintReport 检查结果：结构问题和各级课时合计
+type lintReport struct {
+	Issues   []lintIssue
+	Sections []hourTotal
+	Tables   [][]hourTotal // 与 Sections 一一对应
+	Total    float64
+	Declared string // 文档属性中声明的总课时，未声明时为空
+}
+
+// lintDocument 检查教案的结构：被忽略的行、缺少单元格的五级标题、无法识别的课时，
+// 并按表格和内容区域合计课时，与文档属性中声明的总课时比较
+func lintDocument(source, layoutOverride string) lintReport {
+	doc := parseMarkdown(source)
+	lines := strings.Split(strings.TrimPrefix(source, "\ufeff"), "\n")
+	var report lintReport
+	issue := func(line int, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, lintIssue{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := applyLayout(&doc, layoutOverride); err != nil {
+		issue(frontMatterLine(lines, layoutKey), "%v, checking as %s", err, doc.Layout.Name)
+	}
+	layout := doc.Layout
+
+	for _, line := range doc.Ignored {
+		text := strings.TrimSpace(lines[line-1])
+		switch {
+		case strings.HasPrefix(text, "# "):
+			issue(line, "level 1 heading %q is not part of the layout and is ignored", text)
+		case strings.HasPrefix(text, "#"):
+			issue(line, "heading %q has no parent heading and is ignored", text)
+		default:
+			issue(line, "text outside a ##### block is ignored")
+		}
+	}
+
+	if len(doc.Sections) == 0 {
+		issue(1, "no ## section found")
+	}
+	for _, section := range doc.Sections {
+		sectionTotal := hourTotal{Name: section.H2Title}
+		var tables []hourTotal
+		if len(section.Tables) == 0 {
+			issue(section.Line, "section %q has no ### table", section.H2Title)
+		}
+		for _, table := range section.Tables {
+			tableTotal := hourTotal{Name: table.H3Part1}
+			if table.H3Part2 != "" {
+				tableTotal.Name += "——" + table.H3Part2
+			} else {
+				issue(table.Line, "table heading %q has no %s after ——", table.H3Part1, layout.Labels[1])
+			}
+			grid, tableIssues := activityRows(table, layout)
+			report.Issues = append(report.Issues, tableIssues...)
+			for k, h4 := range table.H4Blocks {
+				if len(h4.H5Blocks) == 0 {
+					issue(h4.Line, "activity %q has no ##### block and is not rendered", h4.Title)
+					continue
+				}
+				for _, h5 := range h4.H5Blocks {
+					switch n, want := len(h5.Content), layout.contentColumns(); {
+					case n < want:
+						issue(h5.Line, "block %q has %d of %d paragraphs, the rest render as empty cells", h5.Title, n, want)
+					case n > want:
+						issue(h5.Line, "block %q has %d paragraphs, only the first %d are rendered", h5.Title, n, want)
+					}
+				}
+
+				// 合并的课时单元格只计算一次
+				hoursCol := layout.hoursColumn()
+				for i, row := range grid[k] {
+					h5 := h4.H5Blocks[i]
+					for col, cell := range row[:hoursCol] {
+						if cell.Rowspan > 0 && col+cell.Colspan > hoursCol {
+							issue(h5.Line, "%s is merged into %s, class hours cannot be read", layout.Columns[hoursCol].Name, layout.Columns[col].Name)
+						}
+					}
+					cell := row[hoursCol]
+					if cell.Rowspan == 0 {
+						continue
+					}
+					hours, ok := parseHours(cell.Content)
+					if strings.TrimSpace(cell.Content) == "" {
+						issue(h5.Line, "block has no class hours")
+						continue
+					}
+					if !ok {
+						issue(h5.Line, "cannot read class hours from %q, expected e.g. 1H or 0.5H", cell.Content)
+						continue
+					}
+					tableTotal.Hours += hours
+				}
+			}
+			tables = append(tables, tableTotal)
+			sectionTotal.Hours += tableTotal.Hours
+		}
+		report.Sections = append(report.Sections, sectionTotal)
+		report.Tables = append(report.Tables, tables)
+		report.Total += sectionTotal.Hours
+	}
+
+	// 与声明的总课时比较
+	for _, field := range doc.Header {
+		if !containsKey(totalHoursKeys, field.Key) || len(field.Values) == 0 {
+			continue
+		}
+		report.Declared = field.Values[0]
+		line := frontMatterLine(lines, field.Key)
+		if declared, ok := parseHours(field.Values[0]); !ok {
+			issue(line, "cannot read total class hours from %s: %q", field.Key, field.Values[0])
+		} else if formatHours(declared) != formatHours(report.Total) {
+			issue(line, "%s declares %s but the blocks add up to %s", field.Key, formatHours(declared), formatHours(report.Total))
+		}
+		break
+	}
+
+	// 按行号排列，同一行保持发现的顺序
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Line < report.Issues[j].Line })
+	return report
+}
+
+// containsKey 判断字段名是否在列表中
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// frontMatterLine 返回文档属性中字段所在的行号
+func frontMatterLine(lines []string, key string) int {
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, key+":") || strings.HasPrefix(line, key+"：") {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// writeLintReport 输出问题和课时合计，问题按 “文件:行号: 说明” 的格式输出
+func writeLintReport(w io.Writer, file string, report lintReport) {
+	for _, issue := range report.Issues {
+		fmt.Fprintf(w, "%s:%d: %s\n", file, issue.Line, issue.Message)
+	}
+	fmt.Fprintf(w, "%s: class hours\n", file)
+	for i, section := range report.Sections {
+		fmt.Fprintf(w, "  %s\t%s\n", section.Name, formatHours(section.Hours))
+		for _, table := range report.Tables[i] {
+			fmt.Fprintf(w, "    %s\t%s\n", table.Name, formatHours(table.Hours))
+		}
+	}
+	if report.Declared != "" {
+		fmt.Fprintf(w, "  total\t%s (declared %s)\n", formatHours(report.Total), report.Declared)
+	} else {
+		fmt.Fprintf(w, "  total\t%s\n", formatHours(report.Total))
+	}
+}
+
+// runLint 检查教案的结构和课时分配，发现问题时以状态码 1 退出
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	layout := fs.String("layout", "", "教案类型，默认读取文档属性中的“教案类型”")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		log.Fatal("usage: shicaojiaoan lint [-layout 教案类型] 文件.md ...")
+	}
+	if *layout != "" {
+		if _, ok := findLayout(*layout); !ok {
+			log.Fatal(unknownLayout(*layout))
+		}
+	}
+	failed := false
+	for _, file := range fs.Args() {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalf("failed to read file: %v", err)
+		}
+		report := lintDocument(string(source), *layout)
+		writeLintReport(os.Stdout, file, report)
+		if len(report.Issues) > 0 {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -3,12 +3,24 @@ shicaojiaoan
 linux darwin windows
 实操教案格式化生成器
 用法: shicaojiaoan [选项] [输入文件]
+      shicaojiaoan import [-o 输出文件] 文件.docx    将 Word 教案转为 Markdown
+      shicaojiaoan lint [-layout 教案类型] 文件.md ...    检查教案结构并合计课时
 
 选项:
+  -addr string
+        预览的监听地址 (default "127.0.0.1:8000")
+  -format string
+        输出格式：typst、docx 或 html (default "typst")
   -h    显示帮助信息
+  -layout string
+        教案类型：practical (实操)、theory (理论) 或 integrated (一体化)，默认读取文档属性中的“教案类型”，均未指定时为实操
   -p    生成 PDF 文件（需要安装 typst）
-  -t    生成空白模板文件 template.md
+  -style string
+        版式：内置样式 (macos, windows, linux) 或 JSON 样式文件，默认按当前系统选择
+  -t    生成与 -layout 对应的空白模板文件 template.md
   -v    显示详细输出信息
+  -watch
+        监视输入文件，变化时重新生成 HTML 并在本地提供自动刷新的预览
 */
 
 package main
@@ -20,6 +32,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -28,26 +41,43 @@ var (
 	isPdf      bool
 	verbose    bool
 	help       bool
+	styleName  string
+	format     string
+	watch      bool
+	addr       string
+	layoutName string
 )
 
-const preamble = `// 中文字号转换函数
-#import "@preview/pointless-size:0.1.2": zh
-#import "@preview/cuti:0.2.1": show-cn-fakebold
-#show: show-cn-fakebold
+// preambleHelpers 文档开头的中文字号函数和伪粗体规则，直接写入文档，编译时无需联网下载包
+const preambleHelpers = `// 中文字号转换函数：zh(5) 为五号，zh(-4) 为小四，也可写 zh("小四")
+#let zh-sizes = (42pt, 26pt, 22pt, 16pt, 14pt, 10.5pt, 7.5pt, 5.5pt, 5pt)
+#let zh-small-sizes = (36pt, 24pt, 18pt, 15pt, 12pt, 9pt, 6.5pt)
+#let zh-names = ("初": 0, "一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6, "七": 7, "八": 8)
+#let zh(size) = {
+  if type(size) == str {
+    let small = size.starts-with("小")
+    let n = zh-names.at(size.trim("小", at: start).trim("号", at: end))
+    if small { zh-small-sizes.at(n) } else { zh-sizes.at(n) }
+  } else if size < 0 {
+    zh-small-sizes.at(-size)
+  } else {
+    zh-sizes.at(size)
+  }
+}
 
-// 定义常用字体名称
-#let FONT_XBS = ("FZXiaoBiaoSong-B05") // 方正小标宋
-#let FONT_HEI = ("STHeiti") // 黑体
-#let FONT_FS = ("STFangsong") // 仿宋
-#let FONT_KAI = ("STKaiti") // 楷体
-#let FONT_SONG = ("STSong") // 宋体
-
-#set page(
-  paper: "a4",
-  flipped: true,
-  margin: (top: 2.54cm, bottom: 2.54cm, left: 2.58cm, right: 2.08cm)
-)
+// 伪粗体：中文字体大多没有粗体字重，为粗体中的汉字和全角标点加描边
+#let show-cn-fakebold(body) = {
+  show strong: it => {
+    show regex("[\\p{script=Han}\u{3000}-\u{303f}\u{ff01}-\u{ff5e}]"): c => context text(stroke: 0.02857em + text.fill, c)
+    it
+  }
+  body
+}
+#show: show-cn-fakebold
+`
 
+// preambleText 页面设置之后的正文和标题样式
+const preambleText = `
 #set text(
   lang: "zh",
   font: FONT_SONG,
@@ -66,12 +96,14 @@ const preamble = `// 中文字号转换函数
 type H5Block struct {
 	Title   string
 	Content []string
+	Line    int // 标题所在的行号，从 1 开始
 }
 
 // H4Block 存储四级标题及其下的所有五级标题块
 type H4Block struct {
 	Title    string
 	H5Blocks []H5Block
+	Line     int
 }
 
 // Table 存储一个三级标题定义的表格
@@ -79,15 +111,41 @@ type Table struct {
 	H3Part1  string
 	H3Part2  string
 	H4Blocks []H4Block
+	Line     int
 }
 
 // DocumentSection 存储一个二级标题定义的内容区域
 type DocumentSection struct {
 	H2Title string
 	Tables  []Table
+	Line    int
+}
+
+// emptyCell 单独成段时表示空单元格，使后面的内容仍对应正确的列；在 Markdown 预览中不显示
+const emptyCell = "<!-- 空 -->"
+
+// Document 存储整篇教案：文档属性中的封面信息和各内容区域
+type Document struct {
+	Header     []HeaderField
+	Sections   []DocumentSection
+	Ignored    []int  // 因缺少上级标题或不在五级标题下而被忽略的非空行的行号
+	LayoutName string // 文档属性中声明的教案类型
+	Layout     Layout // 声明的教案类型，未声明或无法识别时为默认类型
 }
 
-const templateMd = `## 教学活动设计——任务一
+const templateMd = `---
+课程名称:
+授课班级:
+授课教师:
+授课日期:
+课时:
+教学目标:
+  - 
+重难点:
+  - 
+---
+
+## 教学活动设计——任务一
 
 ### 章节标题——任务描述
 
@@ -105,21 +163,42 @@ const templateMd = `## 教学活动设计——任务一
 `
 
 func init() {
-	flag.BoolVar(&isTemplate, "t", false, "生成空白模板文件 template.md")
+	flag.BoolVar(&isTemplate, "t", false, "生成与 -layout 对应的空白模板文件 template.md")
 	flag.BoolVar(&isPdf, "p", false, "生成 PDF 文件（需要安装 typst）")
 	flag.BoolVar(&verbose, "v", false, "显示详细输出信息")
+	flag.StringVar(&format, "format", "typst", "输出格式：typst、docx 或 html")
+	flag.BoolVar(&watch, "watch", false, "监视输入文件，变化时重新生成 HTML 并在本地提供自动刷新的预览")
+	flag.StringVar(&addr, "addr", "127.0.0.1:8000", "预览的监听地址")
+	flag.StringVar(&layoutName, "layout", "", "教案类型：practical (实操)、theory (理论) 或 integrated (一体化)，默认读取文档属性中的“教案类型”，均未指定时为实操")
+	flag.StringVar(&styleName, "style", "", "版式：内置样式 (macos, windows, linux) 或 JSON 样式文件，默认按当前系统选择")
 	flag.BoolVar(&help, "h", false, "显示帮助信息")
 }
 
+// subcommands 子命令，第一个参数为子命令名称时执行
+var subcommands = map[string]func(args []string){
+	"import": runImport,
+	"lint":   runLint,
+}
+
 func printHelp() {
 	fmt.Println("实操教案格式化生成器")
 	fmt.Println("用法: shicaojiaoan [选项] [输入文件]")
+	fmt.Println("      shicaojiaoan import [-o 输出文件] 文件.docx    将 Word 教案转为 Markdown")
+	fmt.Println("      shicaojiaoan lint [-layout 教案类型] 文件.md ...    检查教案结构并合计课时")
 	fmt.Println()
 	fmt.Println("选项:")
 	flag.PrintDefaults()
 }
 
 func main() {
+	// 执行子命令
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if help {
@@ -127,9 +206,13 @@ func main() {
 		return
 	}
 
-	// 处理生成模板的情况
+	// 处理生成模板的情况，模板与 -layout 指定的教案类型对应
 	if isTemplate {
-		if err := ioutil.WriteFile("template.md", []byte(templateMd), 0644); err != nil {
+		layout, ok := findLayout(layoutName)
+		if !ok {
+			log.Fatal(unknownLayout(layoutName))
+		}
+		if err := ioutil.WriteFile("template.md", []byte(layout.Template), 0644); err != nil {
 			log.Fatalf("failed to generate template: %v", err)
 		}
 		if verbose {
@@ -150,9 +233,64 @@ func main() {
 		log.Fatalf("failed to read file: %v", err)
 	}
 
+	if format != "typst" && format != "docx" && format != "html" {
+		log.Fatalf("unknown output format %q, expected typst, docx or html", format)
+	}
+	if isPdf && format != "typst" {
+		log.Fatal("-p only works with the typst output format")
+	}
+
+	style, err := loadStyle(styleName)
+	if err != nil {
+		log.Fatalf("failed to load style: %v", err)
+	}
+	// 预览模式，无需安装 typst
+	if watch {
+		log.Fatal(preview(inputFile, style, layoutName, addr))
+	}
+
+	doc := parseMarkdown(string(source))
+	if err := applyLayout(&doc, layoutName); err != nil {
+		log.Fatal(err)
+	}
+	if err := style.checkColumns(doc.Layout); err != nil {
+		log.Fatal(err)
+	}
+	for _, issue := range mergeIssues(doc) {
+		log.Printf("%s:%d: %s", inputFile, issue.Line, issue.Message)
+	}
+
+	// 生成 HTML 文件
+	if format == "html" {
+		title := strings.TrimSuffix(filepath.Base(inputFile), ".md")
+		outputFile := strings.TrimSuffix(inputFile, ".md") + ".html"
+		if err := ioutil.WriteFile(outputFile, []byte(generateHTML(doc, style, title, "")), 0644); err != nil {
+			log.Fatalf("failed to write file: %v", err)
+		}
+		if verbose {
+			log.Printf("converted %s to %s", inputFile, outputFile)
+		}
+		return
+	}
+
+	// 生成 Word 文档
+	if format == "docx" {
+		output, err := generateDocx(doc, style)
+		if err != nil {
+			log.Fatalf("failed to generate docx: %v", err)
+		}
+		outputFile := strings.TrimSuffix(inputFile, ".md") + ".docx"
+		if err := ioutil.WriteFile(outputFile, output, 0644); err != nil {
+			log.Fatalf("failed to write file: %v", err)
+		}
+		if verbose {
+			log.Printf("converted %s to %s", inputFile, outputFile)
+		}
+		return
+	}
+
 	// 解析并生成 typst
-	sections := parseMarkdown(string(source))
-	typstOutput := generateTypst(sections)
+	typstOutput := generateTypst(doc, style)
 
 	outputFile := strings.TrimSuffix(inputFile, ".md") + ".typ"
 	if err := ioutil.WriteFile(outputFile, []byte(typstOutput), 0644); err != nil {
@@ -183,25 +321,34 @@ func main() {
 	}
 }
 
-// parseMarkdown 将 markdown 字符串解析为 DocumentSection 结构体切片
-func parseMarkdown(content string) []DocumentSection {
-	lines := strings.Split(content, "\n")
+// parseMarkdown 将 markdown 字符串解析为 Document 结构体，开头的文档属性作为封面信息
+func parseMarkdown(content string) Document {
+	lines := strings.Split(strings.TrimPrefix(content, "\ufeff"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r") // 兼容 Windows 换行符
+	}
+	frontMatter, body := splitFrontMatter(lines)
+	offset := len(lines) - len(body) // 文档属性占用的行数
+	lines = body
+
 	var sections []DocumentSection
 	var currentSection *DocumentSection
 	var currentTable *Table
 	var currentH4 *H4Block
 	var currentH5 *H5Block
+	var ignored []int
 
-	for _, line := range lines {
-		line = strings.TrimRight(line, "\r") // 兼容 Windows 换行符
+	for i, line := range lines {
+		lineNumber := offset + i + 1
 		if strings.HasPrefix(line, "## ") {
-			sections = append(sections, DocumentSection{H2Title: strings.TrimSpace(line[3:])})
+			sections = append(sections, DocumentSection{H2Title: strings.TrimSpace(line[3:]), Line: lineNumber})
 			currentSection = &sections[len(sections)-1]
 			currentTable = nil
 			currentH4 = nil
 			currentH5 = nil
 		} else if strings.HasPrefix(line, "### ") {
 			if currentSection == nil {
+				ignored = append(ignored, lineNumber)
 				continue
 			}
 			title := strings.TrimSpace(line[4:])
@@ -222,24 +369,26 @@ func parseMarkdown(content string) []DocumentSection {
 				parts = []string{title, ""}
 			}
 
-			currentSection.Tables = append(currentSection.Tables, Table{H3Part1: strings.TrimSpace(parts[0]), H3Part2: strings.TrimSpace(parts[1])})
+			currentSection.Tables = append(currentSection.Tables, Table{H3Part1: strings.TrimSpace(parts[0]), H3Part2: strings.TrimSpace(parts[1]), Line: lineNumber})
 			currentTable = &currentSection.Tables[len(currentSection.Tables)-1]
 			currentH4 = nil
 			currentH5 = nil
 		} else if strings.HasPrefix(line, "#### ") {
 			if currentTable == nil {
+				ignored = append(ignored, lineNumber)
 				continue
 			}
 			title := strings.TrimSpace(line[5:])
-			currentTable.H4Blocks = append(currentTable.H4Blocks, H4Block{Title: title})
+			currentTable.H4Blocks = append(currentTable.H4Blocks, H4Block{Title: title, Line: lineNumber})
 			currentH4 = &currentTable.H4Blocks[len(currentTable.H4Blocks)-1]
 			currentH5 = nil
 		} else if strings.HasPrefix(line, "##### ") {
 			if currentH4 == nil {
+				ignored = append(ignored, lineNumber)
 				continue
 			}
 			title := strings.TrimSpace(line[6:])
-			currentH4.H5Blocks = append(currentH4.H5Blocks, H5Block{Title: title})
+			currentH4.H5Blocks = append(currentH4.H5Blocks, H5Block{Title: title, Line: lineNumber})
 			currentH5 = &currentH4.H5Blocks[len(currentH4.H5Blocks)-1]
 			// Initialize with one empty content block, ready to be filled.
 			currentH5.Content = []string{""}
@@ -266,6 +415,8 @@ func parseMarkdown(content string) []DocumentSection {
 						currentH5.Content[lastIdx] += "\n" + line
 					}
 				}
+			} else if strings.TrimSpace(line) != "" {
+				ignored = append(ignored, lineNumber)
 			}
 		}
 	}
@@ -279,139 +430,230 @@ func parseMarkdown(content string) []DocumentSection {
 					if len(h5.Content) > 0 && h5.Content[len(h5.Content)-1] == "" {
 						h5.Content = h5.Content[:len(h5.Content)-1]
 					}
+					// 空单元格标记
+					for m := range h5.Content {
+						if strings.TrimSpace(h5.Content[m]) == emptyCell {
+							h5.Content[m] = ""
+						}
+					}
+					if h5.Title == emptyCell {
+						h5.Title = ""
+					}
+				}
+			}
+		}
+	}
+
+	header, layoutName := splitLayoutField(parseFrontMatter(frontMatter))
+	layout, _ := findLayout(layoutName)
+	return Document{Header: header, Sections: sections, Ignored: ignored, LayoutName: layoutName, Layout: layout}
+}
+
+// 合并标记：内容为“同上”的单元格与上方合并，为“同左”的单元格与左侧合并，含其他文字时照常输出
+const (
+	mergeUp   = "同上"
+	mergeLeft = "同左"
+)
+
+// activityCell 教学活动表中的一个单元格
+type activityCell struct {
+	Content string // Markdown 原文
+	Rowspan int    // 合并的行数，0 表示已被其他单元格合并
+	Colspan int    // 合并的列数；被合并的单元格中，位于合并区域首列的与起始单元格相同，供 Word 输出纵向合并，其余为 0
+}
+
+// activityRows 将表格中各五级标题按教案类型的各列排成单元格矩阵，按四级标题分组返回。
+// “同上”可跨越四级标题与上一行合并，“同左”与左侧合并，两者组合可合并矩形区域；
+// 无处合并的标记和不成矩形的合并区域作为问题返回，相应的单元格留空或不合并
+func activityRows(table Table, layout Layout) ([][][]activityCell, []lintIssue) {
+	type position struct{ row, col int }
+
+	var cells [][]activityCell
+	var lines []int
+	for _, h4 := range table.H4Blocks {
+		for _, h5 := range h4.H5Blocks {
+			var row []activityCell
+			for _, content := range layout.cells(h5) {
+				row = append(row, activityCell{Content: content, Rowspan: 1, Colspan: 1})
+			}
+			cells = append(cells, row)
+			lines = append(lines, h5.Line)
+		}
+	}
+
+	// 每个单元格所属合并区域的起始单元格，沿合并链向上或向左查找
+	var issues []lintIssue
+	anchors := make([][]position, len(cells))
+	for r := range cells {
+		anchors[r] = make([]position, len(layout.Columns))
+		for c := range cells[r] {
+			anchors[r][c] = position{r, c}
+			switch strings.TrimSpace(cells[r][c].Content) {
+			case mergeUp:
+				if r == 0 {
+					issues = append(issues, lintIssue{Line: lines[r], Message: fmt.Sprintf("%s in %s has no cell above to merge with", mergeUp, layout.Columns[c].Name)})
+					cells[r][c].Content = ""
+				} else {
+					anchors[r][c] = anchors[r-1][c]
+				}
+			case mergeLeft:
+				if c == 0 {
+					issues = append(issues, lintIssue{Line: lines[r], Message: fmt.Sprintf("%s in %s has no cell to the left to merge with", mergeLeft, layout.Columns[c].Name)})
+					cells[r][c].Content = ""
+				} else {
+					anchors[r][c] = anchors[r][c-1]
 				}
 			}
 		}
 	}
 
-	return sections
+	members := make(map[position][]position)
+	for r := range anchors {
+		for c, anchor := range anchors[r] {
+			if anchor != (position{r, c}) {
+				members[anchor] = append(members[anchor], position{r, c})
+			}
+		}
+	}
+
+	// 合并区域必须是以起始单元格为左上角的矩形，否则不合并
+	for r := range cells {
+		for c := range cells[r] {
+			anchor := position{r, c}
+			covered, ok := members[anchor]
+			if !ok {
+				continue
+			}
+			bottom, right := r, c
+			for _, p := range covered {
+				bottom, right = max(bottom, p.row), max(right, p.col)
+			}
+			rowspan, colspan := bottom-r+1, right-c+1
+			if len(covered)+1 != rowspan*colspan {
+				issues = append(issues, lintIssue{Line: lines[r], Message: fmt.Sprintf("cells merged with %s do not form a rectangle and are left unmerged", layout.Columns[c].Name)})
+				for _, p := range covered {
+					cells[p.row][p.col] = activityCell{Rowspan: 1, Colspan: 1}
+				}
+				continue
+			}
+			cells[r][c].Rowspan, cells[r][c].Colspan = rowspan, colspan
+			for _, p := range covered {
+				cells[p.row][p.col] = activityCell{}
+				if p.col == c {
+					cells[p.row][p.col].Colspan = colspan
+				}
+			}
+		}
+	}
+
+	groups := make([][][]activityCell, len(table.H4Blocks))
+	for i, h4 := range table.H4Blocks {
+		groups[i], cells = cells[:len(h4.H5Blocks)], cells[len(h4.H5Blocks):]
+	}
+	return groups, issues
+}
+
+// mergeIssues 返回文档中所有无法合并的单元格
+func mergeIssues(doc Document) []lintIssue {
+	var issues []lintIssue
+	for _, section := range doc.Sections {
+		for _, table := range section.Tables {
+			_, tableIssues := activityRows(table, doc.Layout)
+			issues = append(issues, tableIssues...)
+		}
+	}
+	return issues
 }
 
 // generateTypst 根据解析出的结构体生成 typst 格式字符串
-func generateTypst(sections []DocumentSection) string {
+func generateTypst(doc Document, style Style) string {
+	layout := doc.Layout
 	var sb strings.Builder
-	sb.WriteString(preamble)
+	sb.WriteString(style.preamble())
+	sb.WriteString(generateHeader(doc.Header))
 
-	for _, section := range sections {
-		sb.WriteString(fmt.Sprintf("\n== %s\n\n", section.H2Title))
+	for _, section := range doc.Sections {
+		sb.WriteString(fmt.Sprintf("\n== %s\n\n", inlineMarkdown(section.H2Title)))
 
 		if len(section.Tables) > 0 {
 			sb.WriteString("#table(\n")
-			sb.WriteString("  columns: (2.3cm, 4.2cm, auto, auto, 2.2cm, 1.1cm),\n")
+			sb.WriteString(fmt.Sprintf("  columns: %s,\n", style.columnsSpec(layout)))
 			sb.WriteString("  stroke: 0.5pt,\n")
 			sb.WriteString("  align: center + horizon,\n")
 
 			for _, table := range section.Tables {
 				// 表格第一行
-				sb.WriteString(fmt.Sprintf("  [*学习环节*], [*%s*], [*学习单元*], table.cell(colspan: 3)[*%s*],\n", table.H3Part1, table.H3Part2))
+				sb.WriteString(fmt.Sprintf("  [*%s*], [*%s*], [*%s*], table.cell(colspan: %d)[*%s*],\n",
+					layout.Labels[0], inlineMarkdown(table.H3Part1), layout.Labels[1], len(layout.Columns)-2, inlineMarkdown(table.H3Part2)))
 
 				// 表格第二行
-				sb.WriteString("  [教学活动], [学习内容], [学生活动], [教师活动], [教学方法与手段], [课时分配],\n")
+				sb.WriteString(" ")
+				for _, heading := range layout.headings() {
+					sb.WriteString(fmt.Sprintf(" [%s],", heading))
+				}
+				sb.WriteString("\n")
 
 				h4Counter := 1 // Reset for each table (H3)
 
 				// 内容行
-				for _, h4 := range table.H4Blocks {
+				grid, _ := activityRows(table, layout)
+				for k, h4 := range table.H4Blocks {
 					if len(h4.H5Blocks) == 0 {
 						continue
 					}
-					// 为当前 H4 构建单元格内容矩阵（每行 5 列：content0, content1, content2, teachingMethods, h5.Title）
-					nRows := len(h4.H5Blocks)
-					cols := 5
-					cellContents := make([][]string, nRows)
-					for i := 0; i < nRows; i++ {
-						h5 := h4.H5Blocks[i]
-						cellContents[i] = make([]string, cols)
-						cellContents[i][0] = getContentLine(h5.Content, 0)
-						cellContents[i][1] = getContentLine(h5.Content, 1)
-						cellContents[i][2] = getContentLine(h5.Content, 2)
-						cellContents[i][3] = getContentLine(h5.Content, 3) // 教学方法，渲染时会替换换行
-						cellContents[i][4] = h5.Title
-					}
-
-					// 初始化 rowspan 矩阵，默认每个单元格 rowspan = 1
-					rowspans := make([][]int, nRows)
-					for i := 0; i < nRows; i++ {
-						rowspans[i] = make([]int, cols)
-						for j := 0; j < cols; j++ {
-							rowspans[i][j] = 1
-						}
-					}
-
-					// 处理包含 "同上" 的单元格：与正上方起始单元格合并（递归合并链）
-					for col := 0; col < cols; col++ {
-						for i := 0; i < nRows; i++ {
-							if strings.Contains(strings.TrimSpace(cellContents[i][col]), "同上") {
-								// 找到上方最近的起始单元格（rowspan != 0）
-								k := i - 1
-								for k >= 0 && rowspans[k][col] == 0 {
-									k--
-								}
-								if k >= 0 {
-									rowspans[k][col]++
-									rowspans[i][col] = 0 // 标记为已被合并，输出时跳过
-								} else {
-									// 若没有上方可合并的单元格（首行），保留为空字符串，不合并
-									cellContents[i][col] = ""
-									rowspans[i][col] = 1
-								}
-							}
-						}
-					}
+					rows := grid[k]
 
-					numberedH4Title := fmt.Sprintf("%d. %s", h4Counter, h4.Title)
+					numberedH4Title := fmt.Sprintf("%d. %s", h4Counter, inlineMarkdown(h4.Title))
 					h4Counter++
 
 					// 为每列在输出时维护独立序号计数器（H4 内重置）
-					counterCol0, counterCol1, counterCol2 := 1, 1, 1
+					counters := layout.startCounters()
 
-					// 输出每一行，依据 rowspans 决定是否输出或输出带 rowspan 的单元格
-					for i := 0; i < nRows; i++ {
+					// 输出每一行，依据 rowspan 决定是否输出或输出带 rowspan 的单元格
+					for i, row := range rows {
 						// 第一列（H4 标题）只在第一行输出，并带有整体 rowspan
 						if i == 0 {
-							sb.WriteString(fmt.Sprintf("  table.cell(rowspan: %d)[%s],", nRows, numberedH4Title))
+							sb.WriteString(fmt.Sprintf("  table.cell(rowspan: %d)[%s],", len(rows), numberedH4Title))
 						}
 
-						// 对应三列内容 + 教学方法 + 课时分配
-						for col := 0; col < cols; col++ {
-							rs := rowspans[i][col]
+						// 对应教案类型的各列
+						for col, cell := range row {
+							rs := cell.Rowspan
 							if rs == 0 {
-								// 被上方合并，跳过输出该单元格
+								// 被其他单元格合并，跳过输出该单元格
 								continue
 							}
 
-							content := cellContents[i][col]
-							// 三列学习类型内容（0..2）：按列编号，调用 formatNumberedContent
-							if col == 0 {
-								formatted, newc := formatNumberedContent(content, counterCol0)
-								counterCol0 = newc
-								content = formatted
-							} else if col == 1 {
-								formatted, newc := formatNumberedContent(content, counterCol1)
-								counterCol1 = newc
-								content = formatted
-							} else if col == 2 {
-								formatted, newc := formatNumberedContent(content, counterCol2)
-								counterCol2 = newc
-								content = formatted
-							} else if col == 3 {
-								// 教学方法列，替换换行为双换行
-								if strings.TrimSpace(content) != "" {
-									content = strings.ReplaceAll(content, "\n", "\n\n")
-								}
+							var content string
+							kind := layout.Columns[col].Kind
+							switch kind {
+							case columnNumbered:
+								// 按列编号
+								content, counters[col] = formatNumberedContent(cell.Content, counters[col])
+							case columnParagraphs:
+								// 每行单独成段
+								content = markdownToTypst(cell.Content)
+							default:
+								content = inlineMarkdown(cell.Content)
 							}
+							left := kind == columnNumbered && strings.TrimSpace(content) != ""
 
-							// 仅在 rowspan > 1 时使用 table.cell
-							if rs > 1 {
+							// 仅在合并单元格时使用 table.cell
+							if rs > 1 || cell.Colspan > 1 {
 								var attrs []string
-								attrs = append(attrs, fmt.Sprintf("rowspan: %d", rs))
-								if col >= 0 && col <= 2 && strings.TrimSpace(content) != "" {
+								if rs > 1 {
+									attrs = append(attrs, fmt.Sprintf("rowspan: %d", rs))
+								}
+								if cell.Colspan > 1 {
+									attrs = append(attrs, fmt.Sprintf("colspan: %d", cell.Colspan))
+								}
+								if left {
 									attrs = append(attrs, "align: left")
 								}
 								sb.WriteString(fmt.Sprintf("  table.cell(%s)[%s],", strings.Join(attrs, ", "), content))
 							} else {
-								// rowspan == 1 时，不使用 table.cell，对齐通过 align() 包裹
-								if col >= 0 && col <= 2 && strings.TrimSpace(content) != "" {
+								// 未合并时不使用 table.cell，对齐通过 align() 包裹
+								if left {
 									sb.WriteString(fmt.Sprintf("  align(left)[%s],", content))
 								} else {
 									sb.WriteString(fmt.Sprintf("  [%s],", content))
@@ -436,18 +678,42 @@ func getContentLine(lines []string, index int) string {
 	return ""
 }
 
-// formatNumberedContent formats content with numbering for each line.
+// cellLine 单元格中的一行：编号行带序号，列表项带层级
+type cellLine struct {
+	listLine
+	Number int // 编号行的序号，列表项为 0
+}
+
+// numberedLines 为每个普通行编号，列表项嵌套在上一个编号行之下
+func numberedLines(content string, startCounter int) ([]cellLine, int) {
+	var lines []cellLine
+	counter := startCounter
+	for _, line := range parseListLines(content) {
+		if line.list {
+			if counter > startCounter {
+				line.level++
+			}
+			lines = append(lines, cellLine{listLine: line})
+			continue
+		}
+		lines = append(lines, cellLine{listLine: line, Number: counter})
+		counter++
+	}
+	return lines, counter
+}
+
+// formatNumberedContent 为每个普通行编号并转为 Typst 标记，列表项嵌套在上一个编号行之下
 func formatNumberedContent(content string, startCounter int) (string, int) {
 	if content == "" {
 		return "", startCounter
 	}
-	lines := strings.Split(content, "\n")
-	var formattedLines []string
-	counter := startCounter
-	for _, line := range lines {
-		if strings.TrimSpace(line) != "" {
-			formattedLines = append(formattedLines, fmt.Sprintf("%d. %s；", counter, line))
-			counter++
+	lines, counter := numberedLines(content, startCounter)
+	formattedLines := make([]string, len(lines))
+	for i, line := range lines {
+		if line.Number == 0 {
+			formattedLines[i] = typstListItem(line.listLine, line.level)
+		} else {
+			formattedLines[i] = fmt.Sprintf("%d. %s；", line.Number, inlineMarkdown(line.text))
 		}
 	}
 	return strings.Join(formattedLines, "\n"), counter
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// spans 将合并结果写成每行一个字符串，单元格写作“行数x列数”，被合并的单元格行数为 0
+func spans(groups [][][]activityCell) []string {
+	var rows []string
+	for _, group := range groups {
+		for _, row := range group {
+			var cells []string
+			for _, cell := range row {
+				cells = append(cells, fmt.Sprintf("%dx%d", cell.Rowspan, cell.Colspan))
+			}
+			rows = append(rows, strings.Join(cells, " "))
+		}
+	}
+	return rows
+}
+
+func TestActivityRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		h4s    [][][]string // 各四级标题下各五级标题的段落，对应实操教案的前四列
+		want   []string
+		issues []string
+	}{
+		{
+			name: "同上跨越四级标题",
+			h4s: [][][]string{
+				{{"讲解", "听讲", "示范", "讲授法"}},
+				{{mergeUp, "练习", "指导", "练习法"}},
+			},
+			want: []string{"2x1 1x1 1x1 1x1 1x1", "0x1 1x1 1x1 1x1 1x1"},
+		},
+		{
+			name: "同左",
+			h4s:  [][][]string{{{"讲解", mergeLeft, mergeLeft, "讲授法"}}},
+			want: []string{"1x3 0x0 0x0 1x1 1x1"},
+		},
+		{
+			name: "矩形",
+			h4s: [][][]string{{
+				{"讲解", mergeLeft, "示范", "讲授法"},
+				{mergeUp, mergeUp, "指导", "练习法"},
+			}},
+			want: []string{"2x2 0x0 1x1 1x1 1x1", "0x2 0x0 1x1 1x1 1x1"},
+		},
+		{
+			name: "不成矩形",
+			h4s: [][][]string{{
+				{"讲解", mergeLeft, "示范", "讲授法"},
+				{mergeUp, "练习", "指导", "练习法"},
+			}},
+			want:   []string{"1x1 1x1 1x1 1x1 1x1", "1x1 1x1 1x1 1x1 1x1"},
+			issues: []string{"cells merged with 学习内容 do not form a rectangle and are left unmerged"},
+		},
+		{
+			name:   "第一行同上",
+			h4s:    [][][]string{{{"讲解", mergeUp, "示范", "讲授法"}}},
+			want:   []string{"1x1 1x1 1x1 1x1 1x1"},
+			issues: []string{"同上 in 学生活动 has no cell above to merge with"},
+		},
+		{
+			name:   "第一列同左",
+			h4s:    [][][]string{{{mergeLeft, "听讲", "示范", "讲授法"}}},
+			want:   []string{"1x1 1x1 1x1 1x1 1x1"},
+			issues: []string{"同左 in 学习内容 has no cell to the left to merge with"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table Table
+			line := 1
+			for _, h4 := range tt.h4s {
+				block := H4Block{Title: "环节", Line: line}
+				for _, content := range h4 {
+					line++
+					block.H5Blocks = append(block.H5Blocks, H5Block{Title: "1H", Content: content, Line: line})
+				}
+				table.H4Blocks = append(table.H4Blocks, block)
+				line++
+			}
+
+			groups, issues := activityRows(table, layouts["practical"])
+			if len(groups) != len(tt.h4s) {
+				t.Fatalf("activityRows() 返回 %d 组, want %d", len(groups), len(tt.h4s))
+			}
+			if got := spans(groups); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("activityRows() 合并结果 = %q, want %q", got, tt.want)
+			}
+			var messages []string
+			for _, issue := range issues {
+				messages = append(messages, issue.Message)
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.issues, "\n") {
+				t.Errorf("activityRows() 问题 = %q, want %q", messages, tt.issues)
+			}
+			// 无处合并的标记不应作为内容输出
+			for _, group := range groups {
+				for _, row := range group {
+					if row[0].Rowspan > 0 && strings.TrimSpace(row[0].Content) == mergeLeft {
+						t.Errorf("第一列中的%s应留空", mergeLeft)
+					}
+				}
+			}
+			if len(groups[0]) > 0 {
+				for c, cell := range groups[0][0] {
+					if cell.Rowspan > 0 && strings.TrimSpace(cell.Content) == mergeUp {
+						t.Errorf("第一行%s中的%s应留空", layouts["practical"].Columns[c].Name, mergeUp)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// previewScript 预览页面每秒检查一次版本号，文件重新生成后自动刷新
+const previewScript = `(function () {
+  var version = "%d";
+  setInterval(function () {
+    fetch("/version").then(function (r) { return r.text(); }).then(function (v) {
+      if (v !== version) { location.reload(); }
+    }).catch(function () {});
+  }, 1000);
+})();
+`
+
+// previewPollInterval 检查输入文件是否变化的间隔
+const previewPollInterval = 500 * time.Millisecond
+
+// previewer 保存最近一次生成的预览页面
+type previewer struct {
+	inputFile string
+	style     Style
+	layout    string // 命令行指定的教案类型
+	host      string // 监听地址中的主机名，用于拒绝 DNS 重绑定的请求
+	mu        sync.Mutex
+	page      string
+	version   int
+}
+
+// render 重新读取输入文件，生成 HTML 文件和带自动刷新的预览页面
+func (p *previewer) render() error {
+	source, err := ioutil.ReadFile(p.inputFile)
+	if err != nil {
+		return err
+	}
+	doc := parseMarkdown(string(source))
+	if err := applyLayout(&doc, p.layout); err != nil {
+		return err
+	}
+	if err := p.style.checkColumns(doc.Layout); err != nil {
+		return err
+	}
+	title := strings.TrimSuffix(filepath.Base(p.inputFile), ".md")
+
+	outputFile := strings.TrimSuffix(p.inputFile, ".md") + ".html"
+	if err := ioutil.WriteFile(outputFile, []byte(generateHTML(doc, p.style, title, "")), 0644); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.version++
+	p.page = generateHTML(doc, p.style, title, fmt.Sprintf(previewScript, p.version))
+	return nil
+}
+
+// watch 定时检查输入文件的修改时间和大小，与上次生成时不同则重新生成
+func (p *previewer) watch(lastMod time.Time, lastSize int64) {
+	for {
+		if info, err := os.Stat(p.inputFile); err == nil && (!info.ModTime().Equal(lastMod) || info.Size() != lastSize) {
+			lastMod, lastSize = info.ModTime(), info.Size()
+			if err := p.render(); err != nil {
+				log.Printf("failed to render %s: %v", p.inputFile, err)
+			} else if verbose {
+				log.Printf("rendered %s", p.inputFile)
+			}
+		}
+		time.Sleep(previewPollInterval)
+	}
+}
+
+// allowedHost 判断请求的 Host 是否指向本机：IP 地址、localhost 或监听地址中的主机名。
+// 其他域名可能是解析到本机的 DNS 重绑定攻击
+func (p *previewer) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	return net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") || (p.host != "" && strings.EqualFold(host, p.host))
+}
+
+// ServeHTTP 提供预览页面和当前版本号
+func (p *previewer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !p.allowedHost(r.Host) {
+		http.Error(w, "host not allowed", http.StatusForbidden)
+		return
+	}
+	p.mu.Lock()
+	page, version := p.page, p.version
+	p.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, page)
+	case "/version":
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, strconv.Itoa(version))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// preview 监视输入文件，变化时重新生成 HTML，并在 addr 上提供自动刷新的预览
+func preview(inputFile string, style Style, layout, addr string) error {
+	info, err := os.Stat(inputFile)
+	if err != nil {
+		return err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	p := &previewer{inputFile: inputFile, style: style, layout: layout, host: host}
+	if err := p.render(); err != nil {
+		return err
+	}
+	go p.watch(info.ModTime(), info.Size())
+
+	log.Printf("previewing %s at http://%s/", inputFile, addr)
+	return http.ListenAndServe(addr, p)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Margin 页边距，使用 Typst 长度，如 "2.54cm"
+type Margin struct {
+	Top    string `json:"top"`
+	Bottom string `json:"bottom"`
+	Left   string `json:"left"`
+	Right  string `json:"right"`
+}
+
+// Fonts 各用途的字体，每项按顺序回退
+type Fonts struct {
+	Title    []string `json:"title"`    // 标题，方正小标宋
+	Heading  []string `json:"heading"`  // 黑体
+	Fangsong []string `json:"fangsong"` // 仿宋
+	Kai      []string `json:"kai"`      // 楷体
+	Body     []string `json:"body"`     // 正文，宋体
+}
+
+// Style 生成文档的版式：纸张、方向、页边距、字体和教学活动表的列宽
+type Style struct {
+	Base        string   `json:"base,omitempty"` // 样式文件基于的内置样式，默认为当前系统的内置样式
+	Paper       string   `json:"paper"`
+	Orientation string   `json:"orientation"` // landscape 或 portrait
+	Margin      Margin   `json:"margin"`
+	Fonts       Fonts    `json:"fonts"`
+	Columns     []string `json:"columns,omitempty"` // 教学活动表各列的宽度，为空时使用教案类型的默认列宽
+}
+
+// defaultMargin 默认页边距
+var defaultMargin = Margin{Top: "2.54cm", Bottom: "2.54cm", Left: "2.58cm", Right: "2.08cm"}
+
+// builtinStyles 内置样式，仅字体不同
+var builtinStyles = map[string]Style{
+	"macos": {
+		Paper: "a4", Orientation: "landscape", Margin: defaultMargin,
+		Fonts: Fonts{
+			Title:    []string{"FZXiaoBiaoSong-B05"},
+			Heading:  []string{"STHeiti"},
+			Fangsong: []string{"STFangsong"},
+			Kai:      []string{"STKaiti"},
+			Body:     []string{"STSong"},
+		},
+	},
+	"windows": {
+		Paper: "a4", Orientation: "landscape", Margin: defaultMargin,
+		Fonts: Fonts{
+			Title:    []string{"FZXiaoBiaoSong-B05S", "FZXiaoBiaoSong-B05", "SimHei"},
+			Heading:  []string{"SimHei", "Microsoft YaHei"},
+			Fangsong: []string{"FangSong", "FangSong_GB2312"},
+			Kai:      []string{"KaiTi", "KaiTi_GB2312"},
+			Body:     []string{"SimSun", "NSimSun"},
+		},
+	},
+	"linux": {
+		Paper: "a4", Orientation: "landscape", Margin: defaultMargin,
+		Fonts: Fonts{
+			Title:    []string{"FZXiaoBiaoSong-B05", "Noto Serif CJK SC", "Source Han Serif SC"},
+			Heading:  []string{"Noto Sans CJK SC", "Source Han Sans SC"},
+			Fangsong: []string{"FangSong", "Noto Serif CJK SC"},
+			Kai:      []string{"AR PL UKai CN", "KaiTi", "Noto Serif CJK SC"},
+			Body:     []string{"Noto Serif CJK SC", "Source Han Serif SC"},
+		},
+	},
+}
+
+// defaultStyleName 返回当前系统对应的内置样式名称
+func defaultStyleName() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "macos"
+	case "windows":
+		return "windows"
+	}
+	return "linux"
+}
+
+// styleNames 返回所有内置样式的名称
+func styleNames() []string {
+	var names []string
+	for name := range builtinStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadStyle 读取样式：name 为内置样式名称或 JSON 样式文件路径，为空时使用当前系统的内置样式。
+// 样式文件只需写出与基础样式不同的项
+func loadStyle(name string) (Style, error) {
+	if name == "" {
+		name = defaultStyleName()
+	}
+	if style, ok := builtinStyles[strings.ToLower(name)]; ok {
+		return style, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Style{}, fmt.Errorf("unknown style %q (built-in styles: %s): %v", name, strings.Join(styleNames(), ", "), err)
+	}
+	var override struct {
+		Base string `json:"base"`
+	}
+	if err := json.Unmarshal(data, &override); err != nil {
+		return Style{}, fmt.Errorf("failed to parse style file %s: %v", name, err)
+	}
+	if override.Base == "" {
+		override.Base = defaultStyleName()
+	}
+	base, ok := builtinStyles[strings.ToLower(override.Base)]
+	if !ok {
+		return Style{}, fmt.Errorf("unknown base style %q in %s", override.Base, name)
+	}
+	style := base.clone()
+	if err := json.Unmarshal(data, &style); err != nil {
+		return Style{}, fmt.Errorf("failed to parse style file %s: %v", name, err)
+	}
+	if err := style.validate(); err != nil {
+		return Style{}, fmt.Errorf("style file %s: %v", name, err)
+	}
+	return style, nil
+}
+
+// frRegex 教学活动表中按比例分配的列宽，如 1fr、1.5fr
+var frRegex = regexp.MustCompile(`^\d+(\.\d+)?fr$`)
+
+// validate 检查样式文件中的纸张、方向、页边距和列宽，纸张名称统一为小写
+func (s *Style) validate() error {
+	s.Paper = strings.ToLower(strings.TrimSpace(s.Paper))
+	if _, ok := paperSizes[s.Paper]; !ok {
+		var papers []string
+		for paper := range paperSizes {
+			papers = append(papers, paper)
+		}
+		sort.Strings(papers)
+		return fmt.Errorf("unknown paper %q (supported: %s)", s.Paper, strings.Join(papers, ", "))
+	}
+	if s.Orientation != "landscape" && s.Orientation != "portrait" {
+		return fmt.Errorf("orientation must be landscape or portrait")
+	}
+	for _, margin := range []struct{ side, length string }{
+		{"top", s.Margin.Top}, {"bottom", s.Margin.Bottom}, {"left", s.Margin.Left}, {"right", s.Margin.Right},
+	} {
+		if twips, ok := lengthTwips(margin.length); !ok || twips < 0 {
+			return fmt.Errorf("invalid %s margin %q, expected a length such as 2.54cm, 25mm, 72pt or 1in", margin.side, margin.length)
+		}
+	}
+	for i, column := range s.Columns {
+		if _, ok := lengthTwips(column); !ok && column != "auto" && !frRegex.MatchString(column) {
+			return fmt.Errorf("invalid width %q for column %d, expected a length such as 2.3cm, auto or 1fr", column, i+1)
+		}
+	}
+	return nil
+}
+
+// clone 复制样式，避免解析样式文件时改写内置样式共用的切片
+func (s Style) clone() Style {
+	copied := func(list []string) []string {
+		return append([]string(nil), list...)
+	}
+	s.Columns = copied(s.Columns)
+	s.Fonts = Fonts{
+		Title:    copied(s.Fonts.Title),
+		Heading:  copied(s.Fonts.Heading),
+		Fangsong: copied(s.Fonts.Fangsong),
+		Kai:      copied(s.Fonts.Kai),
+		Body:     copied(s.Fonts.Body),
+	}
+	return s
+}
+
+// typstFonts 将字体列表转为 Typst 数组，只有一项时也保留为数组
+func typstFonts(fonts []string) string {
+	quoted := make([]string, len(fonts))
+	for i, font := range fonts {
+		quoted[i] = typstString(font)
+	}
+	if len(quoted) == 1 {
+		return "(" + quoted[0] + ",)"
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// columnsSpec 返回教学活动表的 columns 参数
+func (s Style) columnsSpec(layout Layout) string {
+	return "(" + strings.Join(s.activityWidths(layout), ", ") + ")"
+}
+
+// preamble 生成文档开头的字号函数、字体定义和页面设置
+func (s Style) preamble() string {
+	var sb strings.Builder
+	sb.WriteString(preambleHelpers)
+	sb.WriteString("\n// 定义常用字体名称\n")
+	sb.WriteString(fmt.Sprintf("#let FONT_XBS = %s // 方正小标宋\n", typstFonts(s.Fonts.Title)))
+	sb.WriteString(fmt.Sprintf("#let FONT_HEI = %s // 黑体\n", typstFonts(s.Fonts.Heading)))
+	sb.WriteString(fmt.Sprintf("#let FONT_FS = %s // 仿宋\n", typstFonts(s.Fonts.Fangsong)))
+	sb.WriteString(fmt.Sprintf("#let FONT_KAI = %s // 楷体\n", typstFonts(s.Fonts.Kai)))
+	sb.WriteString(fmt.Sprintf("#let FONT_SONG = %s // 宋体\n", typstFonts(s.Fonts.Body)))
+	sb.WriteString(fmt.Sprintf(`
+#set page(
+  paper: %s,
+  flipped: %t,
+  margin: (top: %s, bottom: %s, left: %s, right: %s)
+)
+`, typstString(s.Paper), s.Orientation == "landscape", s.Margin.Top, s.Margin.Bottom, s.Margin.Left, s.Margin.Right))
+	sb.WriteString(preambleText)
+	return sb.String()
+}
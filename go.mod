@@ -7,6 +7,7 @@ toolchain go1.24.6
 require (
 	github.com/AlecAivazis/survey/v2 v2.3.7
 	github.com/xuri/excelize/v2 v2.10.0
+	golang.org/x/text v0.30.0
 	howett.net/plist v1.0.1
 )
 
@@ -28,7 +29,6 @@ require (
 	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
 	golang.org/x/sys v0.37.0 // indirect
 	golang.org/x/term v0.36.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
 )
 
 // replace github.com/mrered/gobin/pkg/reportgen => ./pkg/reportgen
@@ -0,0 +1,316 @@
+package reportgen
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// tally 统计表中的一行
+type tally struct {
+	Name  string
+	Count int
+}
+
+// tallies 按名称累计次数
+type tallies map[string]int
+
+// sorted 按次数从多到少排序，次数相同时按名称排序
+func (t tallies) sorted() []tally {
+	rows := make([]tally, 0, len(t))
+	for name, count := range t {
+		rows = append(rows, tally{name, count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// teachingStats 一个学期教学部分的统计数据
+type teachingStats struct {
+	total   int                // 教学记录总数
+	courses tallies            // 各课程的记录数
+	tags    tallies            // 各标签的记录数
+	weeks   tallies            // 各周的记录数
+	matrix  map[string]tallies // 各课程下各标签的记录数
+}
+
+// collectTeachingStats 统计日报教学部分中各课程、各标签和各周的记录数
+func collectTeachingStats(reports []Report, terms *Terms) *teachingStats {
+	stats := &teachingStats{
+		courses: tallies{},
+		tags:    tallies{},
+		weeks:   tallies{},
+		matrix:  make(map[string]tallies),
+	}
+	for _, report := range reports {
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			continue
+		}
+		week := extractWeekFromContent(report.Content, terms.WeekKey)
+		for _, entry := range extractEntries(report, date) {
+			if entry.Section != terms.Teaching || (entry.Course == "" && len(entry.Tags) == 0) {
+				continue
+			}
+			stats.total++
+			if week != "" {
+				stats.weeks[week]++
+			}
+			for _, tag := range entry.Tags {
+				stats.tags[tag]++
+			}
+			if entry.Course == "" {
+				continue
+			}
+			stats.courses[entry.Course]++
+			if stats.matrix[entry.Course] == nil {
+				stats.matrix[entry.Course] = tallies{}
+			}
+			for _, tag := range entry.Tags {
+				stats.matrix[entry.Course][tag]++
+			}
+		}
+	}
+	return stats
+}
+
+// sortedWeeks 按周数先后排序
+func (s *teachingStats) sortedWeeks() []tally {
+	rows := make([]tally, 0, len(s.weeks))
+	for week, count := range s.weeks {
+		rows = append(rows, tally{week, count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, errA := strconv.Atoi(rows[i].Name)
+		b, errB := strconv.Atoi(rows[j].Name)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// displayWidth 返回文本在等宽字体下的显示宽度，全角字符计为 2
+func displayWidth(text string) int {
+	n := 0
+	for _, r := range text {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// asciiBarChart 生成文本条形图，最长的条为 barWidth 个字符
+func asciiBarChart(rows []tally, barWidth int) string {
+	labelWidth, max := 0, 0
+	for _, row := range rows {
+		if w := displayWidth(row.Name); w > labelWidth {
+			labelWidth = w
+		}
+		if row.Count > max {
+			max = row.Count
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		length := 0
+		if max > 0 {
+			length = (row.Count*barWidth + max - 1) / max
+		}
+		sb.WriteString(row.Name)
+		sb.WriteString(strings.Repeat(" ", labelWidth-displayWidth(row.Name)))
+		sb.WriteString(fmt.Sprintf(" | %s %d\n", strings.Repeat("█", length), row.Count))
+	}
+	return sb.String()
+}
+
+// svgBarChart 生成横向条形图的 SVG 文件内容
+func svgBarChart(title string, rows []tally) string {
+	const (
+		labelWidth = 180
+		barWidth   = 400
+		rowHeight  = 28
+		top        = 40
+	)
+	max := 0
+	for _, row := range rows {
+		if row.Count > max {
+			max = row.Count
+		}
+	}
+	height := top + rowHeight*len(rows) + 10
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="PingFang SC, Microsoft YaHei, Noto Sans CJK SC, sans-serif" font-size="14">`+"\n", labelWidth+barWidth+60, height))
+	sb.WriteString(fmt.Sprintf(`<text x="10" y="24" font-size="16" font-weight="bold">%s</text>`+"\n", html.EscapeString(title)))
+	for i, row := range rows {
+		y := top + rowHeight*i
+		length := 0
+		if max > 0 {
+			length = row.Count * barWidth / max
+		}
+		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", labelWidth-10, y+18, html.EscapeString(row.Name)))
+		sb.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="#4a7ebb"/>`+"\n", labelWidth, y+4, length, rowHeight-8))
+		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d">%d</text>`+"\n", labelWidth+length+6, y+18, row.Count))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// writeTallyTable 输出“名称 | 次数 | 占比”表格
+func writeTallyTable(sb *strings.Builder, header string, rows []tally, total int, locale Locale) {
+	sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", header, locale.T("次数"), locale.T("占比")))
+	sb.WriteString("| --- | ---: | ---: |\n")
+	for _, row := range rows {
+		share := 0.0
+		if total > 0 {
+			share = float64(row.Count) * 100 / float64(total)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %.1f%% |\n", strings.ReplaceAll(row.Name, "|", "\\|"), row.Count, share))
+	}
+}
+
+// renderAnalytics 生成教学统计报告正文
+func renderAnalytics(stats *teachingStats, chartName string, locale Locale) string {
+	var sb strings.Builder
+	courses := stats.courses.sorted()
+	tags := stats.tags.sorted()
+	weeks := stats.sortedWeeks()
+
+	sb.WriteString(fmt.Sprintf("## %s\n\n", locale.T("按课程统计")))
+	writeTallyTable(&sb, locale.T("课程"), courses, stats.total, locale)
+	sb.WriteString(fmt.Sprintf("\n![[%s]]\n", chartName))
+
+	if len(tags) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", locale.T("按标签统计")))
+		writeTallyTable(&sb, locale.T("标签"), tags, stats.total, locale)
+		sb.WriteString("\n```text\n" + asciiBarChart(tags, 30) + "```\n")
+
+		// 课程与标签交叉表
+		tagNames := make([]string, len(tags))
+		for i, tag := range tags {
+			tagNames[i] = tag.Name
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", locale.T("课程与标签")))
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", locale.T("课程"), strings.Join(tagNames, " | ")))
+		sb.WriteString("| --- |" + strings.Repeat(" ---: |", len(tagNames)) + "\n")
+		for _, course := range courses {
+			sb.WriteString("| " + strings.ReplaceAll(course.Name, "|", "\\|") + " |")
+			for _, tag := range tagNames {
+				sb.WriteString(fmt.Sprintf(" %d |", stats.matrix[course.Name][tag]))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(weeks) > 0 {
+		labels := make([]tally, len(weeks))
+		for i, week := range weeks {
+			labels[i] = tally{locale.Sprintf("第 %s 周", week.Name), week.Count}
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", locale.T("按周统计")))
+		writeTallyTable(&sb, locale.T("周"), labels, stats.total, locale)
+		sb.WriteString("\n```text\n" + asciiBarChart(labels, 30) + "```\n")
+	}
+
+	return sb.String()
+}
+
+// Generate 生成指定学期的教学统计报告
+func (g *AnalyticsGenerator) Generate(sourcePath string, params map[string]string) error {
+	// 读取日报文件
+	reports, err := g.readFiles(sourcePath)
+	if err != nil {
+		return g.Config.Locale.Errorf("读取日报文件失败：%v", err)
+	}
+	terms := g.terms()
+
+	// 筛选指定学期的日报
+	start, end, err := SemesterRange(g.Config.SelectedPeriod)
+	if err != nil {
+		return g.Config.Locale.Errorf("未找到 %s 学期的日报", g.Config.SelectedPeriod)
+	}
+	selectedReports := selectDailyReports(reports, start, end)
+	stats := collectTeachingStats(selectedReports, terms)
+	if stats.total == 0 {
+		return g.Config.Locale.Errorf("%s 学期的日报中没有教学记录", g.Config.SelectedPeriod)
+	}
+
+	// 生成文档属性
+	var frontMatter strings.Builder
+	frontMatter.WriteString("---\n")
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.CourseCountKey, len(stats.courses)))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.TeachingCountKey, stats.total))
+	frontMatter.WriteString("---\n\n")
+
+	// 统计报告的目录不是必需目录，按需创建
+	if err := os.MkdirAll(g.Config.TargetDir, 0755); err != nil {
+		return err
+	}
+
+	// 写入课程条形图
+	chartName := g.terms().AnalyticsFile(g.Config.SelectedPeriod, ".svg")
+	chart := svgBarChart(g.Config.Locale.Sprintf("%s 各课程教学次数", g.Config.SelectedPeriod), stats.courses.sorted())
+	if err := g.Config.Backup.WriteFile(filepath.Join(g.Config.TargetDir, chartName), []byte(chart)); err != nil {
+		return err
+	}
+
+	content := frontMatter.String() + renderAnalytics(stats, chartName, g.Config.Locale)
+	outputFile := filepath.Join(g.Config.TargetDir, g.terms().AnalyticsFile(g.Config.SelectedPeriod, ".md"))
+
+	// 写入文件
+	return g.writeReport(outputFile, content, newSidecar("a", g.Config.SelectedPeriod, start, end, selectedReports))
+}
+
+// GetAvailablePeriods 获取日报覆盖的学期
+func (g *AnalyticsGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
+	reports, err := g.readFiles(sourcePath)
+	if err != nil {
+		return nil, g.Config.Locale.Errorf("读取日报文件失败：%v", err)
+	}
+
+	semesterMap := make(map[string]*Period)
+	for _, report := range reports {
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			continue
+		}
+
+		semester := GetSemesterPeriod(date)
+		if _, ok := semesterMap[semester]; !ok {
+			start, end, err := SemesterRange(semester)
+			if err != nil {
+				continue
+			}
+			semesterMap[semester] = &Period{
+				Kind:      "a",
+				Key:       semester,
+				Label:     semester,
+				Target:    g.terms().AnalyticsFile(semester, ".md"),
+				Generated: g.targetExists(g.terms().AnalyticsFile(semester, ".md")),
+			}
+			extendPeriod(semesterMap[semester], start, end)
+			continue
+		}
+		semesterMap[semester].Sources++
+	}
+
+	return sortedPeriods(semesterMap), nil
+}
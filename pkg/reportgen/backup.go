@@ -0,0 +1,214 @@
+package reportgen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackupDir 工作目录下保存被覆盖报告的目录，每次运行一个子目录
+const BackupDir = ".reportgen/backup"
+
+// DefaultBackupKeep 默认保留的备份次数
+const DefaultBackupKeep = 20
+
+// backupManifestFile 每次运行的备份目录中记录所写文件的清单
+const backupManifestFile = "manifest.json"
+
+// BackupEntry 一次运行中写入的一个文件
+type BackupEntry struct {
+	Path    string `json:"path"`    // 相对于工作目录的路径
+	Existed bool   `json:"existed"` // 写入前文件是否已存在，已存在时备份目录中保存了原文件
+}
+
+// Backup 在覆盖报告前备份原文件，同一次运行写入的文件共用一个备份目录
+type Backup struct {
+	root    string // 工作目录
+	keep    int    // 保留的备份次数
+	run     string // 本次运行的备份目录，首次写入时创建
+	entries []BackupEntry
+	saved   map[string]bool
+	mu      sync.Mutex
+}
+
+// NewBackup 创建一次运行的备份，keep 不大于 0 时使用 DefaultBackupKeep
+func NewBackup(workDir string, keep int) *Backup {
+	if keep <= 0 {
+		keep = DefaultBackupKeep
+	}
+	return &Backup{root: workDir, keep: keep, saved: make(map[string]bool)}
+}
+
+// next 创建与当前备份设置相同的新一次运行，b 为空时返回空
+func (b *Backup) next() *Backup {
+	if b == nil {
+		return nil
+	}
+	return NewBackup(b.root, b.keep)
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名，避免写入中断时损坏原文件
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteFile 备份已存在的文件后原子地写入新内容，b 为空时只原子写入
+func (b *Backup) WriteFile(path string, data []byte) error {
+	if b == nil {
+		return writeFileAtomic(path, data)
+	}
+	if err := b.save(path); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// save 在本次运行中首次写入某个文件前记录并备份该文件
+func (b *Backup) save(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 工作目录之外的文件不备份
+	rel, err := filepath.Rel(b.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	if b.saved[rel] {
+		return nil
+	}
+
+	if b.run == "" {
+		b.run = filepath.Join(b.root, BackupDir, time.Now().Format("20060102-150405.000000"))
+		if err := os.MkdirAll(b.run, 0755); err != nil {
+			return err
+		}
+		if err := pruneBackups(b.root, b.keep); err != nil {
+			return err
+		}
+	}
+
+	entry := BackupEntry{Path: rel}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		entry.Existed = true
+		target := filepath.Join(b.run, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	b.saved[rel] = true
+	b.entries = append(b.entries, entry)
+	manifest, err := json.MarshalIndent(b.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(b.run, backupManifestFile), manifest)
+}
+
+// backupRuns 按时间先后返回工作目录下所有运行的备份目录名
+func backupRuns(workDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(workDir, BackupDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Strings(runs)
+	return runs, nil
+}
+
+// pruneBackups 删除超出保留次数的旧备份
+func pruneBackups(workDir string, keep int) error {
+	runs, err := backupRuns(workDir)
+	if err != nil {
+		return err
+	}
+	for len(runs) > keep {
+		if err := os.RemoveAll(filepath.Join(workDir, BackupDir, runs[0])); err != nil {
+			return err
+		}
+		runs = runs[1:]
+	}
+	return nil
+}
+
+// Undo 撤销最近一次运行：恢复被覆盖的文件，删除新建的文件，并删除该次备份
+func Undo(workDir string, locale Locale) ([]BackupEntry, error) {
+	runs, err := backupRuns(workDir)
+	if err != nil {
+		return nil, locale.Errorf("读取备份目录失败：%v", err)
+	}
+	if len(runs) == 0 {
+		return nil, locale.Errorf("没有可以撤销的操作")
+	}
+	run := filepath.Join(workDir, BackupDir, runs[len(runs)-1])
+
+	data, err := os.ReadFile(filepath.Join(run, backupManifestFile))
+	if err != nil {
+		return nil, locale.Errorf("读取备份清单失败：%v", err)
+	}
+	var entries []BackupEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, locale.Errorf("读取备份清单失败：%v", err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(workDir, entry.Path)
+		if !entry.Existed {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			continue
+		}
+		original, err := os.ReadFile(filepath.Join(run, entry.Path))
+		if err != nil {
+			return nil, locale.Errorf("读取备份文件失败：%v", err)
+		}
+		if err := writeFileAtomic(path, original); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, os.RemoveAll(run)
+}
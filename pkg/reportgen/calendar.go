@@ -0,0 +1,151 @@
+package reportgen
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Duty 杂事部分中的一项值守任务，如查宿、监考、值班
+type Duty struct {
+	Date    time.Time // 日报日期
+	Start   time.Time // 开始时间，未写明时间段时为零值，表示全天
+	End     time.Time // 结束时间，跨午夜时为次日
+	Keyword string    // 匹配到的关键词
+	Text    string    // 去除序号后的原文
+	File    string
+}
+
+// AllDay 判断任务是否未写明时间段
+func (d Duty) AllDay() bool {
+	return d.Start.IsZero()
+}
+
+var (
+	dutyTimeRangeRegex = regexp.MustCompile(`(\d{1,2})[:：](\d{2})\s*(?:-|–|—|~|～|至|到)\s*(\d{1,2})[:：](\d{2})`)
+	dutyNumberingRegex = regexp.MustCompile(`^\d+[.、)）]\s*`)
+)
+
+// dutyKeywords 返回识别值守任务的关键词，较长的关键词在前，使“特种工监考”优先于“监考”
+func (t *Terms) dutyKeywords() []string {
+	var keywords []string
+	for _, keyword := range []string{t.ExamKeyword, t.DormKeyword, t.InvigilationKeyword, t.DutyKeyword} {
+		if keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// parseDuty 解析一行杂事，不含值守关键词时返回 false
+func parseDuty(line string, date time.Time, terms *Terms) (Duty, bool) {
+	text := strings.TrimSpace(dutyNumberingRegex.ReplaceAllString(strings.TrimSpace(line), ""))
+	for _, keyword := range terms.dutyKeywords() {
+		if !strings.Contains(text, keyword) {
+			continue
+		}
+		duty := Duty{Date: date, Keyword: keyword, Text: text}
+		if match := dutyTimeRangeRegex.FindStringSubmatch(text); match != nil {
+			clock := func(hour, minute string) time.Time {
+				h, _ := strconv.Atoi(hour)
+				m, _ := strconv.Atoi(minute)
+				return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, time.Local)
+			}
+			duty.Start = clock(match[1], match[2])
+			duty.End = clock(match[3], match[4])
+			if !duty.End.After(duty.Start) {
+				duty.End = duty.End.AddDate(0, 0, 1)
+			}
+		}
+		return duty, true
+	}
+	return Duty{}, false
+}
+
+// ExtractDuties 从记录的杂事部分中提取值守任务
+func ExtractDuties(entries []Entry, terms *Terms) []Duty {
+	if terms == nil {
+		terms = DefaultTerms()
+	}
+	var duties []Duty
+	for _, entry := range entries {
+		if entry.Section != terms.Miscellaneous {
+			continue
+		}
+		for _, line := range strings.Split(entry.Text, "\n") {
+			if duty, ok := parseDuty(line, entry.Date, terms); ok {
+				duty.File = entry.File
+				duties = append(duties, duty)
+			}
+		}
+	}
+	return duties
+}
+
+// icsEscape 按 RFC 5545 转义文本值
+func icsEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// icsLine 写入一行内容，超过 75 字节时按 RFC 5545 折行，不拆分多字节字符
+func icsLine(sb *strings.Builder, line string) {
+	const limit = 75
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	sb.WriteString(line + "\r\n")
+}
+
+// WriteICS 将值守任务写为 iCalendar 文件，每项任务一个事件。
+// 写明时间段的任务使用不带时区的本地时间，其余任务为全天事件
+func WriteICS(w io.Writer, duties []Duty, name string) error {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	var sb strings.Builder
+
+	icsLine(&sb, "BEGIN:VCALENDAR")
+	icsLine(&sb, "VERSION:2.0")
+	icsLine(&sb, "PRODID:-//mrered//reportgen//ZH")
+	icsLine(&sb, "CALSCALE:GREGORIAN")
+	icsLine(&sb, "METHOD:PUBLISH")
+	if name != "" {
+		icsLine(&sb, "X-WR-CALNAME:"+icsEscape(name))
+	}
+
+	seen := make(map[string]int)
+	for _, duty := range duties {
+		// 同一篇日报中的同一行内容生成相同的 UID，重复导入时更新而不是新增事件
+		key := filepath.Base(duty.File) + "\n" + duty.Text
+		seen[key]++
+		uid := fmt.Sprintf("%x-%d@reportgen", sha1.Sum([]byte(key)), seen[key])
+
+		icsLine(&sb, "BEGIN:VEVENT")
+		icsLine(&sb, "UID:"+uid)
+		icsLine(&sb, "DTSTAMP:"+stamp)
+		if duty.AllDay() {
+			icsLine(&sb, "DTSTART;VALUE=DATE:"+duty.Date.Format("20060102"))
+			icsLine(&sb, "DTEND;VALUE=DATE:"+duty.Date.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			icsLine(&sb, "DTSTART:"+duty.Start.Format("20060102T150405"))
+			icsLine(&sb, "DTEND:"+duty.End.Format("20060102T150405"))
+		}
+		icsLine(&sb, "SUMMARY:"+icsEscape(duty.Keyword))
+		icsLine(&sb, "DESCRIPTION:"+icsEscape(duty.Text))
+		icsLine(&sb, "CATEGORIES:"+icsEscape(duty.Keyword))
+		icsLine(&sb, "END:VEVENT")
+	}
+
+	icsLine(&sb, "END:VCALENDAR")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,168 @@
+package reportgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NamedRange 定义了一个自定义时间段，如“期中考试周”
+type NamedRange struct {
+	Name  string `json:"name"`
+	Start string `json:"start"` // 起始日期，格式 YYYY-MM-DD 或 YYYYMMDD
+	End   string `json:"end"`   // 结束日期（含），格式同上
+}
+
+// parseRangeDate 解析自定义时间段中的日期
+func parseRangeDate(value string, locale Locale) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "20060102", "2006/01/02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, locale.Errorf("无法解析日期 %s", value)
+}
+
+// Dates 返回时间段的起止日期
+func (r NamedRange) Dates(locale Locale) (time.Time, time.Time, error) {
+	start, err := parseRangeDate(r.Start, locale)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseRangeDate(r.End, locale)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, locale.Errorf("结束日期 %s 早于起始日期 %s", r.End, r.Start)
+	}
+	return start, end, nil
+}
+
+// findRange 按名称查找自定义时间段
+func (g *CustomGenerator) findRange(name string) (NamedRange, error) {
+	for _, r := range g.Config.Ranges {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return NamedRange{}, g.Config.Locale.Errorf("未找到名为 %s 的自定义时间段", name)
+}
+
+// customOutputName 生成自定义时间段报告的文件名
+func customOutputName(name string) string {
+	return fmt.Sprintf("%s.md", strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(name))
+}
+
+// selectDailyReports 筛选日期在起止范围内的日报
+func selectDailyReports(reports []Report, start, end time.Time) []Report {
+	var selected []Report
+	for _, report := range reports {
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			continue
+		}
+		if !date.Before(start) && !date.After(end) {
+			selected = append(selected, report)
+		}
+	}
+	return selected
+}
+
+// Generate 生成自定义时间段报告
+func (g *CustomGenerator) Generate(sourcePath string, params map[string]string) error {
+	r, err := g.findRange(g.Config.SelectedPeriod)
+	if err != nil {
+		return err
+	}
+	start, end, err := r.Dates(g.Config.Locale)
+	if err != nil {
+		return g.Config.Locale.Errorf("自定义时间段 %s 无效：%v", r.Name, err)
+	}
+
+	// 读取日报文件
+	reports, err := g.readFiles(sourcePath)
+	if err != nil {
+		return g.Config.Locale.Errorf("读取日报文件失败：%v", err)
+	}
+	terms := g.terms()
+
+	// 按文件名中的日期筛选日报
+	selectedReports := selectDailyReports(reports, start, end)
+	if len(selectedReports) == 0 {
+		return g.Config.Locale.Errorf("未找到 %s 至 %s 的日报", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+
+	// 生成日报链接列表
+	var dailyLinks strings.Builder
+	for _, report := range selectedReports {
+		fileName := strings.TrimSuffix(filepath.Base(report.FilePath), ".md")
+		dailyLinks.WriteString(fmt.Sprintf("[[%s]]\n\n", fileName))
+	}
+
+	// 合并报告内容并格式化，统计各项数据
+	content, listeningCount, dormCount, examCount := countDailyStats(g.mergeSectionsAndFormat(selectedReports), terms)
+
+	// 生成文档属性
+	var frontMatter strings.Builder
+	frontMatter.WriteString("---\n")
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%s\"\n", terms.RangeNameKey, r.Name))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%s\"\n", terms.StartDateKey, start.Format("2006-01-02")))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%s\"\n", terms.EndDateKey, end.Format("2006-01-02")))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ListeningCountKey, listeningCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.DormCountKey, dormCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ExamCountKey, examCount))
+	frontMatter.WriteString("---\n\n")
+
+	// 调用大模型生成总结
+	content, err = g.appendSummary(content)
+	if err != nil {
+		return err
+	}
+
+	// 在内容前添加日报链接和文档属性
+	content = frontMatter.String() + dailyLinks.String() + content
+
+	// 自定义时间段报告的目录不是必需目录，按需创建
+	if err := os.MkdirAll(g.Config.TargetDir, 0755); err != nil {
+		return err
+	}
+	outputFile := filepath.Join(g.Config.TargetDir, customOutputName(r.Name))
+
+	// 写入文件
+	sidecar := newSidecar("c", r.Name, start, end, selectedReports)
+	sidecar.Counters = &SidecarCounters{Listening: listeningCount, Dorm: dormCount, Exam: examCount}
+	return g.writeReport(outputFile, content, sidecar)
+}
+
+// GetAvailablePeriods 获取配置文件中定义的自定义时间段
+func (g *CustomGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
+	reports, err := g.readFiles(sourcePath)
+	if err != nil {
+		return nil, g.Config.Locale.Errorf("读取日报文件失败：%v", err)
+	}
+
+	var periods []Period
+	for _, r := range g.Config.Ranges {
+		start, end, err := r.Dates(g.Config.Locale)
+		if err != nil {
+			continue
+		}
+		periods = append(periods, Period{
+			Kind:      "c",
+			Key:       r.Name,
+			Label:     r.Name,
+			Start:     start,
+			End:       end,
+			Sources:   len(selectDailyReports(reports, start, end)),
+			Target:    customOutputName(r.Name),
+			Generated: g.targetExists(customOutputName(r.Name)),
+		})
+	}
+
+	SortPeriods(periods)
+	return periods, nil
+}
@@ -0,0 +1,76 @@
+package reportgen
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// byteOrderMarks 支持的字节顺序标记及其对应的编码
+var byteOrderMarks = []struct {
+	mark     []byte
+	encoding encoding.Encoding
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, nil}, // UTF-8，只需去除标记
+	{[]byte{0xFF, 0xFE}, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{[]byte{0xFE, 0xFF}, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+}
+
+// decodeText 将笔记内容转换为 UTF-8：去除字节顺序标记，识别 UTF-16 和 GBK 编码，并统一换行符为 \n
+func decodeText(data []byte, locale Locale) (string, error) {
+	var text string
+	decoded := false
+	for _, bom := range byteOrderMarks {
+		if !bytes.HasPrefix(data, bom.mark) {
+			continue
+		}
+		data = data[len(bom.mark):]
+		if bom.encoding != nil {
+			converted, err := bom.encoding.NewDecoder().Bytes(data)
+			if err != nil {
+				return "", err
+			}
+			text = string(converted)
+			decoded = true
+		}
+		break
+	}
+
+	if !decoded {
+		if utf8.Valid(data) {
+			text = string(data)
+		} else {
+			// 不是 UTF-8 时按 GB18030 解码，GB18030 兼容 GBK 和 GB2312
+			converted, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+			if err != nil || bytes.ContainsRune(converted, utf8.RuneError) {
+				return "", locale.Errorf("既不是 UTF-8 也不是 GBK 编码")
+			}
+			text = string(converted)
+		}
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n"), nil
+}
+
+// readNote 读取一篇笔记并转换为 UTF-8
+func readNote(path string, locale Locale) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return decodeText(data, locale)
+}
+
+// splitLines 按行拆分内容，行长度不受限制，末尾的换行符不产生空行
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,272 @@
+package reportgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Teacher 部门汇总中的一位教师
+type Teacher struct {
+	Name    string // 显示名称
+	WorkDir string // 该教师的工作目录
+	terms   *Terms
+}
+
+// Department 将多位教师已生成的月报或学期报合并为部门报告
+type Department struct {
+	Teachers  []Teacher
+	OutputDir string // 部门报告的输出目录，与各教师的工作目录分开
+	Terms     *Terms // 部门报告使用的词汇，读取自输出目录下的配置文件
+	Locale    Locale
+	Backup    *Backup // 覆盖部门报告前的备份，为空时不备份
+	Sidecar   bool    // 是否同时输出 .json 数据文件
+}
+
+// NewDepartment 读取各教师工作目录下的配置文件并检查目录结构
+func NewDepartment(teachers []Teacher, outputDir string, locale Locale) (*Department, error) {
+	if len(teachers) == 0 {
+		return nil, locale.Errorf("未指定任何教师的工作目录")
+	}
+
+	names := make(map[string]bool)
+	for i := range teachers {
+		t := &teachers[i]
+		if names[t.Name] {
+			return nil, locale.Errorf("教师名称重复：%s", t.Name)
+		}
+		names[t.Name] = true
+
+		settings, err := LoadSettings(t.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		if t.terms, err = settings.ResolveTerms(); err != nil {
+			return nil, err
+		}
+		if err := ValidateWorkingDir(t.WorkDir, t.terms, locale); err != nil {
+			return nil, locale.Errorf("%s：%v", t.Name, err)
+		}
+	}
+
+	settings, err := LoadSettings(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := settings.ResolveTerms()
+	if err != nil {
+		return nil, err
+	}
+	return &Department{Teachers: teachers, OutputDir: outputDir, Terms: terms, Locale: locale}, nil
+}
+
+// departmentLevel 返回部门报告使用的目录名和文件名，只支持月报和学期报
+func departmentLevel(terms *Terms, reportType, period string) (string, string, bool) {
+	switch reportType {
+	case "m":
+		return terms.MonthlyDir, monthlyOutputName(period), true
+	case "s":
+		return terms.SemesterDir, terms.SemesterFile(period), true
+	}
+	return "", "", false
+}
+
+// config 返回指定教师在指定报告类型下的配置
+func (t *Teacher) config(reportType string, locale Locale) *Config {
+	source, target, _ := t.terms.LevelDirs(reportType)
+	return &Config{
+		WorkDir:    t.WorkDir,
+		SourceDir:  filepath.Join(t.WorkDir, source),
+		TargetDir:  filepath.Join(t.WorkDir, target),
+		ReportType: reportType,
+		Locale:     locale,
+		Terms:      t.terms,
+	}
+}
+
+// Periods 返回至少一位教师已生成报告的时间段，Sources 为已生成报告的教师人数
+func (d *Department) Periods(reportType string) ([]Period, error) {
+	if _, _, ok := departmentLevel(d.Terms, reportType, ""); !ok {
+		return nil, d.Locale.Errorf("部门汇总只支持月报 (m) 和学期报 (s)")
+	}
+
+	periodMap := make(map[string]*Period)
+	for i := range d.Teachers {
+		config := d.Teachers[i].config(reportType, d.Locale)
+		generator, err := NewGenerator(config)
+		if err != nil {
+			return nil, err
+		}
+		periods, err := generator.GetAvailablePeriods(config.SourceDir)
+		if err != nil {
+			return nil, d.Locale.Errorf("%s：%v", d.Teachers[i].Name, err)
+		}
+		for _, period := range periods {
+			if !period.Generated {
+				continue
+			}
+			if existing, ok := periodMap[period.Key]; ok {
+				existing.Sources++
+				continue
+			}
+			_, name, _ := departmentLevel(d.Terms, reportType, period.Key)
+			period.Kind = "d"
+			period.Sources = 1
+			period.Target = name
+			period.Generated = d.targetExists(reportType, period.Key)
+			periodMap[period.Key] = &period
+		}
+	}
+	return sortedPeriods(periodMap), nil
+}
+
+// targetExists 判断部门报告是否已存在
+func (d *Department) targetExists(reportType, period string) bool {
+	dir, name, _ := departmentLevel(d.Terms, reportType, period)
+	_, err := os.Stat(filepath.Join(d.OutputDir, dir, name))
+	return err == nil
+}
+
+// headingLineRegex 匹配一至五级标题，用于将教师报告中的标题降一级
+var headingLineRegex = regexp.MustCompile(`^(#{1,5}) `)
+
+// demoteHeadings 将内容中的标题降一级，使其位于教师小节之下
+func demoteHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = headingLineRegex.ReplaceAllString(line, "#$1 ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// teacherCounters 各教师报告中的统计数据
+type teacherCounters struct {
+	name   string
+	values []int
+}
+
+// Generate 合并各教师指定时间段的报告，写入部门报告
+func (d *Department) Generate(reportType, period string) error {
+	dir, name, ok := departmentLevel(d.Terms, reportType, period)
+	if !ok {
+		return d.Locale.Errorf("部门汇总只支持月报 (m) 和学期报 (s)")
+	}
+
+	g := &BaseGenerator{Config: &Config{Locale: d.Locale, Terms: d.Terms, Sidecar: d.Sidecar, Backup: d.Backup}}
+	sections := append(d.Terms.Sections(), d.Terms.Summary)
+	merged := make([]strings.Builder, len(sections))
+
+	var counters []teacherCounters
+	var missing, sources []string
+	for i := range d.Teachers {
+		t := &d.Teachers[i]
+		teacherDir, teacherName, _ := departmentLevel(t.terms, reportType, period)
+		path := filepath.Join(t.WorkDir, teacherDir, teacherName)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			missing = append(missing, t.Name)
+			continue
+		}
+		if err != nil {
+			return d.Locale.Errorf("%s：%v", t.Name, err)
+		}
+		sources = append(sources, t.Name+"/"+strings.TrimSuffix(teacherName, ".md"))
+
+		// 优先读取数据文件，没有数据文件或数据文件早于报告时读取报告正文。
+		// 统计数据按位置对应，各教师可以使用不同的词汇
+		entry := teacherCounters{name: t.Name}
+		var teacherSections map[string][]string
+		if sidecar := g.readSidecar(Report{FilePath: path, ModTime: info.ModTime()}); sidecar != nil && sidecar.Counters != nil {
+			entry.values = []int{sidecar.Counters.Listening, sidecar.Counters.Dorm, sidecar.Counters.Exam}
+			teacherSections = sidecar.sectionMap()
+		} else {
+			content, err := readNote(path, d.Locale)
+			if err != nil {
+				return d.Locale.Errorf("%s：%v", t.Name, err)
+			}
+			for _, key := range []string{t.terms.ListeningCountKey, t.terms.DormCountKey, t.terms.ExamCountKey} {
+				n, _ := strconv.Atoi(frontMatterValue(content, key))
+				entry.values = append(entry.values, n)
+			}
+			teacherSections = g.extractSections(content)
+		}
+		counters = append(counters, entry)
+
+		// 各部分按位置对应，写入该教师的小节
+		for j, section := range append(t.terms.Sections(), t.terms.Summary) {
+			body := strings.TrimSpace(strings.Join(teacherSections[section], "\n"))
+			if body == "" {
+				continue
+			}
+			merged[j].WriteString(fmt.Sprintf("### %s\n\n%s\n\n", t.Name, demoteHeadings(body)))
+		}
+	}
+
+	if len(counters) == 0 {
+		return d.Locale.Errorf("所有教师均未生成 %s 的报告", period)
+	}
+
+	// 生成文档属性和统计表
+	keys := []string{d.Terms.ListeningCountKey, d.Terms.DormCountKey, d.Terms.ExamCountKey}
+	totals := make([]int, len(keys))
+	var table strings.Builder
+	table.WriteString(fmt.Sprintf("| %s | %s |\n", d.Locale.T("教师"), strings.Join(keys, " | ")))
+	table.WriteString("| --- |" + strings.Repeat(" ---: |", len(keys)) + "\n")
+	for _, entry := range counters {
+		table.WriteString("| " + entry.name + " |")
+		for j, n := range entry.values {
+			totals[j] += n
+			table.WriteString(fmt.Sprintf(" %d |", n))
+		}
+		table.WriteString("\n")
+	}
+	table.WriteString("| " + d.Locale.T("合计") + " |")
+	for _, n := range totals {
+		table.WriteString(fmt.Sprintf(" %d |", n))
+	}
+	table.WriteString("\n")
+
+	var frontMatter strings.Builder
+	frontMatter.WriteString("---\n")
+	for j, key := range keys {
+		frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", key, totals[j]))
+	}
+	frontMatter.WriteString("---\n\n")
+
+	var content strings.Builder
+	content.WriteString(frontMatter.String())
+	content.WriteString(table.String())
+	if len(missing) > 0 {
+		content.WriteString("\n" + d.Locale.Sprintf("未提交报告：%s", strings.Join(missing, "、")) + "\n")
+	}
+	for j, section := range sections {
+		if merged[j].Len() == 0 {
+			continue
+		}
+		content.WriteString(fmt.Sprintf("\n## %s\n\n", section))
+		content.WriteString(strings.TrimRight(merged[j].String(), "\n") + "\n")
+	}
+
+	// 部门报告的输出目录不是教师的工作目录，按需创建
+	targetDir := filepath.Join(d.OutputDir, dir)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return err
+	}
+
+	// 写入文件，数据文件中的来源为“教师/报告名”
+	var start, end time.Time
+	if reportType == "m" {
+		start, _ = time.Parse("200601", period)
+		end = start.AddDate(0, 1, -1)
+	} else {
+		start, end, _ = SemesterRange(period)
+	}
+	sidecar := newSidecar("d", period, start, end, nil)
+	sidecar.Sources = append(sidecar.Sources, sources...)
+	sidecar.Counters = &SidecarCounters{Listening: totals[0], Dorm: totals[1], Exam: totals[2]}
+	return g.writeReport(filepath.Join(targetDir, name), content.String(), sidecar)
+}
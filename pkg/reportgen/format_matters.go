@@ -1,29 +1,36 @@
 package reportgen
 
 import (
-	"bufio"
 	"fmt"
 	"strings"
 )
 
 // MiscellaneousFormatter 杂事部分的格式化器
-type MiscellaneousFormatter struct{}
+type MiscellaneousFormatter struct {
+	Keywords []string // 需要保留的数字开头行的关键词，为空时使用“查宿”和“监考”
+}
 
 // Format 实现了 SectionFormatter 接口
 func (f *MiscellaneousFormatter) Format(content string) string {
 	var nonNumberLines []string
 	var importantNumberLines []string
 
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	for scanner.Scan() {
-		line := scanner.Text()
+	keywords := f.Keywords
+	if len(keywords) == 0 {
+		keywords = []string{"查宿", "监考"}
+	}
+
+	for _, line := range splitLines(content) {
 		trimmedLine := strings.TrimSpace(line)
 
 		// 检查是否以数字开头
 		if len(trimmedLine) > 0 && (trimmedLine[0] >= '0' && trimmedLine[0] <= '9') {
 			// 检查是否包含关键词
-			if strings.Contains(trimmedLine, "查宿") || strings.Contains(trimmedLine, "监考") {
-				importantNumberLines = append(importantNumberLines, trimmedLine)
+			for _, keyword := range keywords {
+				if strings.Contains(trimmedLine, keyword) {
+					importantNumberLines = append(importantNumberLines, trimmedLine)
+					break
+				}
 			}
 			// 跳过其他数字开头的行
 			continue
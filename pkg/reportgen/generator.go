@@ -1,8 +1,8 @@
 package reportgen
 
 import (
-	"bufio"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -33,6 +33,16 @@ type YearlyGenerator struct {
 	BaseGenerator
 }
 
+// CustomGenerator 自定义时间段报告生成器
+type CustomGenerator struct {
+	BaseGenerator
+}
+
+// AnalyticsGenerator 教学统计报告生成器
+type AnalyticsGenerator struct {
+	BaseGenerator
+}
+
 // NewGenerator 创建对应类型的报告生成器
 func NewGenerator(config *Config) (ReportGenerator, error) {
 	switch config.ReportType {
@@ -44,32 +54,76 @@ func NewGenerator(config *Config) (ReportGenerator, error) {
 		return &SemesterGenerator{BaseGenerator{config}}, nil
 	case "y":
 		return &YearlyGenerator{BaseGenerator{config}}, nil
+	case "c":
+		return &CustomGenerator{BaseGenerator{config}}, nil
+	case "a":
+		return &AnalyticsGenerator{BaseGenerator{config}}, nil
 	default:
-		return nil, fmt.Errorf("不支持的报告类型：%s", config.ReportType)
+		return nil, config.Locale.Errorf("不支持的报告类型：%s", config.ReportType)
+	}
+}
+
+// terms 返回当前使用的词汇
+func (g *BaseGenerator) terms() *Terms {
+	if g.Config.Terms != nil {
+		return g.Config.Terms
+	}
+	return DefaultTerms()
+}
+
+// targetExists 判断目标目录下是否已存在指定文件
+func (g *BaseGenerator) targetExists(name string) bool {
+	if g.Config.TargetDir == "" {
+		return false
 	}
+	_, err := os.Stat(filepath.Join(g.Config.TargetDir, name))
+	return err == nil
 }
 
-// readFiles 读取指定目录下的所有 Markdown 文件
+// readFiles 读取指定目录下的所有 Markdown 文件，无法解码的文件跳过并在标准错误中给出警告
 func (g *BaseGenerator) readFiles(sourcePath string) ([]Report, error) {
+	reports, undecodable, err := g.readNotes(sourcePath)
+	for _, issue := range undecodable {
+		fmt.Fprintln(os.Stderr, g.Config.Locale.Sprintf("警告：已跳过 %s：%s", issue.File, issue.Message))
+	}
+	return reports, err
+}
+
+// readNotes 读取指定目录下的所有 Markdown 文件，并返回无法解码的文件
+func (g *BaseGenerator) readNotes(sourcePath string) ([]Report, []Issue, error) {
 	var reports []Report
-	err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+	var undecodable []Issue
+	fsys, root := g.sourceFS(sourcePath)
+	err := fs.WalkDir(fsys, root, func(name string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
-			content, err := os.ReadFile(path)
+		if !entry.IsDir() && strings.HasSuffix(strings.ToLower(name), ".md") {
+			// 报告路径仍以 sourcePath 为前缀，与从磁盘读取时一致
+			path := filepath.Join(sourcePath, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(name, root), "/")))
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			data, err := fs.ReadFile(fsys, name)
 			if err != nil {
 				return err
 			}
+			content, err := decodeText(data, g.Config.Locale)
+			if err != nil {
+				undecodable = append(undecodable, Issue{IssueEncoding, path, g.Config.Locale.Sprintf("无法解码：%v", err)})
+				return nil
+			}
 			report := Report{
 				FilePath: path,
-				Content:  string(content),
+				Content:  content,
+				ModTime:  info.ModTime(),
 			}
 			reports = append(reports, report)
 		}
 		return nil
 	})
-	return reports, err
+	return reports, undecodable, err
 }
 
 // extractSections 从报告内容中提取各个部分
@@ -78,9 +132,7 @@ func (g *BaseGenerator) extractSections(content string) map[string][]string {
 	currentSection := ""
 	var currentContent []string
 
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range splitLines(content) {
 		if strings.HasPrefix(line, "## ") {
 			if currentSection != "" && len(currentContent) > 0 {
 				sections[currentSection] = append(sections[currentSection], strings.Join(currentContent, "\n"))
@@ -104,7 +156,11 @@ func (g *BaseGenerator) extractSections(content string) map[string][]string {
 func (g *BaseGenerator) mergeSections(reports []Report) map[string][]string {
 	merged := make(map[string][]string)
 	for _, report := range reports {
+		// 下级报告有数据文件时优先读取数据文件
 		sections := g.extractSections(report.Content)
+		if sidecar := g.readSidecar(report); sidecar != nil {
+			sections = sidecar.sectionMap()
+		}
 		for section, content := range sections {
 			merged[section] = append(merged[section], content...)
 		}
@@ -119,25 +175,26 @@ func (g *BaseGenerator) mergeSectionsAndFormat(reports []Report) string {
 
 	// 第二步：格式化合并后的内容
 	var result strings.Builder
-	sectionOrder := []string{TeachingSection, ListeningSection, TrainingSection, MiscellaneousSection}
+	terms := g.terms()
+	sectionOrder := terms.Sections()
 
 	// 初始化格式化器
 	var formatters map[string]SectionFormatter
 	// 如果是周报，使用周报的格式化器
 	// 根据生成器类型初始化对应的格式化器
 	switch g.Config.ReportType {
-	case "w":
+	case "w", "c":
 		formatters = map[string]SectionFormatter{
-			TeachingSection:      &TeachingFormatter{},
-			ListeningSection:     &ListeningFormatter{},
-			MiscellaneousSection: &MiscellaneousFormatter{},
+			terms.Teaching:      &TeachingFormatter{},
+			terms.Listening:     &ListeningFormatter{},
+			terms.Miscellaneous: &MiscellaneousFormatter{Keywords: []string{terms.DormKeyword, terms.InvigilationKeyword}},
 		}
 	case "m", "s":
 		formatters = map[string]SectionFormatter{
-			TeachingSection:      &MonthlyTeachingFormatter{},
-			ListeningSection:     &MonthlyListeningFormatter{},
-			TrainingSection:      &MonthlyTrainingFormatter{},
-			MiscellaneousSection: &MonthlyMattersFormatter{},
+			terms.Teaching:      &MonthlyTeachingFormatter{},
+			terms.Listening:     &MonthlyListeningFormatter{},
+			terms.Training:      &MonthlyTrainingFormatter{},
+			terms.Miscellaneous: &MonthlyMattersFormatter{},
 		}
 	}
 
@@ -0,0 +1,251 @@
+package reportgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Locale 表示界面语言
+type Locale string
+
+// 支持的界面语言
+const (
+	LocaleZhCN Locale = "zh-CN"
+	LocaleEn   Locale = "en"
+)
+
+// catalogs 各语言的消息目录，以简体中文原文为键，缺失的条目回退到原文
+var catalogs = map[Locale]map[string]string{
+	LocaleEn: {
+		// pkg/reportgen
+		"不支持的报告类型：%s":             "unsupported report type: %s",
+		"当前目录不完整，无法归纳总结：%s 目录不存在": "incomplete working directory, cannot summarise: directory %s does not exist",
+		"读取日报文件失败：%v":             "failed to read daily notes: %v",
+		"读取周报文件失败：%v":             "failed to read weekly reports: %v",
+		"读取月报文件失败：%v":             "failed to read monthly reports: %v",
+		"读取学期报文件失败：%v":            "failed to read semester reports: %v",
+		"未找到第 %s 周的日报":            "no daily notes found for week %s",
+		"未找到 %s 月的周报":             "no weekly reports found for month %s",
+		"未找到 %s 学期的月报":            "no monthly reports found for semester %s",
+		"未找到 %s 学期的日报":            "no daily notes found for semester %s",
+		"未找到 %s 年的学期报":            "no semester reports found for year %s",
+		"读取提示词模板失败：%v":            "failed to read prompt template: %v",
+		"解析提示词模板失败：%v":            "failed to parse prompt template: %v",
+		"生成提示词失败：%v":              "failed to render prompt: %v",
+		"生成“%s”部分总结失败：%v":         "failed to summarise section \"%s\": %v",
+		"创建缓存目录失败：%v":             "failed to create cache directory: %v",
+		"写入缓存失败：%v":               "failed to write cache: %v",
+		"接口返回 %s：%s":              "endpoint returned %s: %s",
+		"解析接口返回内容失败：%v":           "failed to parse endpoint response: %v",
+		"接口返回错误：%s":               "endpoint returned an error: %s",
+		"接口返回内容为空":                "endpoint returned an empty reply",
+		"读取配置文件失败：%v":             "failed to read configuration file: %v",
+		"解析配置文件 %s 失败：%v":         "failed to parse configuration file %s: %v",
+		"不支持的词汇预设：%s":             "unsupported vocabulary preset: %s",
+		"未找到名为 %s 的自定义时间段":        "no custom period named %s",
+		"自定义时间段 %s 无效：%v":         "invalid custom period %s: %v",
+		"未找到 %s 至 %s 的日报":         "no daily notes found from %s to %s",
+		"第 %s 周":                  "Week %s",
+		"%d 年 %d 月":               "%d-%02d",
+		"%s 学年":                   "%s academic year",
+		"警告：已跳过 %s：%s":            "warning: skipped %s: %s",
+		"无法解码：%v":                 "cannot decode: %v",
+		"读取备份目录失败：%v":             "failed to read backup directory: %v",
+		"没有可以撤销的操作":               "nothing to undo",
+		"读取备份清单失败：%v":             "failed to read backup manifest: %v",
+		"读取备份文件失败：%v":             "failed to read backup file: %v",
+		"已恢复 %s":                  "restored %s",
+		"已删除 %s":                  "removed %s",
+		"读取 git 版本 %s 失败：%v":      "failed to read git revision %s: %v",
+		"打开归档 %s 失败：%v":           "failed to open archive %s: %v",
+		"不支持的笔记来源：%s":             "unsupported note source: %s",
+		"未指定任何教师的工作目录":            "no teacher working directories given",
+		"教师名称重复：%s":               "duplicate teacher name: %s",
+		"%s：%v":                   "%s: %v",
+		"部门汇总只支持月报 (m) 和学期报 (s)":  "department roll-up only supports monthly (m) and semester (s) reports",
+		"所有教师均未生成 %s 的报告":         "no teacher has generated a report for %s",
+		"未提交报告：%s":                "Reports not submitted: %s",
+		"教师":                      "Teacher",
+		"合计":                      "Total",
+		"%d 位教师":                  "%d teachers",
+		"打开排班表失败：%v":              "failed to open roster: %v",
+		"读取排班表失败：%v":              "failed to read roster: %v",
+		"读取工作表 %s 失败：%v":          "failed to read sheet %s: %v",
+		"不支持的排班表格式：%s":            "unsupported roster format: %s",
+		"排班表中未找到日期、教师和任务三列":       "the roster has no date, teacher and duty columns",
+		"排班表第 %d 行：%v":            "roster row %d: %v",
+		"排班表第 %d 行":               "roster row %d",
+		"遗漏":                      "Missed",
+		"排班表外":                    "Not on roster",
+		"调班":                      "Swapped",
+		"问题":                      "Problem",
+		"任务":                      "Duty",
+		"排班日期":                    "Scheduled",
+		"日报日期":                    "Recorded",
+		"%s 学期的日报中没有教学记录":         "no teaching entries in the daily notes of semester %s",
+		"%s 各课程教学次数":              "Teaching sessions per course, %s",
+		"按课程统计":                   "By course",
+		"按标签统计":                   "By tag",
+		"按周统计":                    "By week",
+		"课程与标签":                   "Courses and tags",
+		"次数":                      "Sessions",
+		"占比":                      "Share",
+		"周":                       "Week",
+		"%d 篇日报":                  "%d daily notes",
+		"%d 篇周报":                  "%d weekly reports",
+		"%d 篇月报":                  "%d monthly reports",
+		"%d 篇学期报":                 "%d semester reports",
+		"%d 个源文件":                 "%d source files",
+		"已生成":                     "generated",
+		"读取 %s 目录失败：%v":           "failed to read directory %s: %v",
+		"无法从文件名中提取日期":             "cannot extract a date from the file name",
+		"缺少“%s”属性，无法归入任何周报":       "missing \"%s\" property, cannot be assigned to a weekly report",
+		"%s 的日报未被任何周报覆盖":          "daily note of %s is not covered by any weekly report",
+		"周报跨越 %s 与 %s，整周计入 %s 月报，其中 %d 篇日报属于 %s": "weekly report spans %s and %s and is counted entirely in %s; %d daily notes belong to %s",
+		"%s 在报告生成后被修改，需要重新生成":                    "%s was modified after this report was generated; regenerate it",
+		"引用的 %s 已不存在":                            "linked report %s no longer exists",
+		"缺少 %s，需要重新生成":                           "missing %s; regenerate it",
+		"被 %s 重复计入":                              "counted more than once, by %s",
+		"%s 为 %d，但引用的报告合计为 %d":                   "%s is %d but the linked reports add up to %d",
+		"未找到时间段 %s":                              "period %s not found",
+		"周报":                                     "Weekly reports",
+		"月报":                                     "Monthly reports",
+		"学期报":                                    "Semester reports",
+		"年报":                                     "Yearly reports",
+		"自定义时间段":                                 "Custom periods",
+		"教学统计":                                   "Teaching analytics",
+		"生成":                                     "Generate",
+		"重新生成":                                   "Regenerate",
+		"返回列表":                                   "Back to list",
+		"报告尚未生成":                                 "This report has not been generated yet",
+		"不允许的主机名：%s":                             "host not allowed: %s",
+		"拒绝来自其他站点的请求":                            "rejected a request from another site",
+		"查询语句有误：%v":                              "invalid query: %v",
+		"不支持的查询条件：%s":                            "unsupported query field: %s",
+		"查询语句有误：%s 条件不支持排除":                      "invalid query: %s cannot be negated",
+		"不支持的输出格式：%s":                            "unsupported output format: %s",
+		"日期":                                     "Date",
+		"部分":                                     "Section",
+		"课程":                                     "Course",
+		"标签":                                     "Tags",
+		"内容":                                     "Text",
+		"来源":                                     "Source",
+		"无法解析日期 %s":                              "cannot parse date %s",
+		"结束日期 %s 早于起始日期 %s":                      "end date %s is before start date %s",
+		"既不是 UTF-8 也不是 GBK 编码":                   "neither UTF-8 nor GBK encoded",
+		"引号未闭合":                                  "unterminated quote",
+		"无法解析 git cat-file 输出：%s":                "cannot parse git cat-file output: %s",
+		"无法从文件名 %s 中提取日期":                        "cannot extract a date from file name %s",
+		"无法从月报文件名 %s 中提取日期":                      "cannot extract a date from monthly report name %s",
+		"无法解析学期 %s":                              "cannot parse semester %s",
+		// cmd/reportgen
+		"reportgen 版本:":      "reportgen version:",
+		"生成报告":               "Generate reports",
+		"用法: reportgen [选项]": "Usage: reportgen [options]",
+		"选项:":                "Options:",
+		"指定工作目录":             "working directory",
+		"是否格式化内容":            "format the merged content",
+		"指定报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报, c: 自定义时间段, a: 教学统计)": "report type (w: weekly, m: monthly, s: semester, y: yearly, c: custom period, a: teaching analytics)",
+		"指定周数":              "week number",
+		"指定月份 (格式: YYYYMM)": "month (format: YYYYMM)",
+		"指定学期 (格式: YYYY - YYYY 春/秋)，也用于教学统计": "semester (format: YYYY - YYYY 春/秋), also used by teaching analytics",
+		"指定年份 (格式: YYYY)":                    "year (format: YYYY)",
+		"显示帮助信息":                             "show help",
+		"显示版本号":                              "show version",
+		"界面语言 (zh-CN, en)，默认读取 LANG":         "interface language (zh-CN, en), defaults to LANG",
+		"大模型对话接口地址 (如 http://localhost:11434/api/chat)，指定后生成总结部分": "LLM chat endpoint (e.g. http://localhost:11434/api/chat); enables the summary section",
+		"大模型名称":                   "LLM model name",
+		"大模型接口超时时间":               "LLM request timeout",
+		"错误：必须指定工作目录 (-d)":        "error: a working directory is required (-d)",
+		"报告生成完成":                  "Report generated",
+		"归纳周报 (w)":                "Summarise weekly report (w)",
+		"归纳月报 (m)":                "Summarise monthly report (m)",
+		"归纳学期报 (s)":               "Summarise semester report (s)",
+		"归纳自定义时间段 (c)":            "Summarise custom period (c)",
+		"部门报告的输出目录":               "output directory for department reports",
+		"合并的报告类型 (m: 月报, s: 学期报)": "report type to merge (m: monthly, s: semester)",
+		"时间段 (月报: YYYYMM, 学期报: YYYY - YYYY 春/秋)，不指定时列出可选的时间段": "period (monthly: YYYYMM, semester: YYYY - YYYY 春/秋); lists available periods when omitted",
+		"错误：必须指定输出目录 (-o)":                                    "error: output directory (-o) is required",
+		"同时输出与报告同名的 .json 数据文件":                               "also write a .json data file next to each report",
+		"无法解码的文件": "Undecodable files",
+		"笔记来源：目录、.zip 归档或 git:版本 (如 git:HEAD~1)，默认为工作目录": "where to read notes from: a directory, a .zip archive or git:REVISION (e.g. git:HEAD~1); defaults to the working directory",
+		"输出文件，默认输出到标准输出":                                 "output file; defaults to standard output",
+		"日历名称":                         "calendar name",
+		"已导出 %d 项任务到 %s":               "exported %d duties to %s",
+		"排班表文件 (.xlsx 或 .csv)":         "roster file (.xlsx or .csv)",
+		"排班表所在的工作表，默认为第一个工作表":          "sheet containing the roster; defaults to the first sheet",
+		"排班表中的教师姓名":                    "teacher name as written on the roster",
+		"指定学期 (格式: YYYY - YYYY 春/秋)":   "semester (format: YYYY - YYYY 春/秋)",
+		"请使用 -r 指定排班表，-teacher 指定教师姓名": "use -r to give the roster and -teacher to give the teacher name",
+		"无效的月份：%s":                     "invalid month: %s",
+		"无效的学期：%s":                     "invalid semester: %s",
+		"请使用 -m 指定月份或 -s 指定学期":         "use -m to give a month or -s to give a semester",
+		"统计教学情况 (a)":                   "Teaching analytics (a)",
+		"自定义时间段名称 (配置文件中的 ranges，或与 -from/-to 一同指定)": "custom period name (from ranges in the configuration file, or together with -from/-to)",
+		"自定义时间段的起始日期 (格式: YYYY-MM-DD)":               "start date of the custom period (format: YYYY-MM-DD)",
+		"自定义时间段的结束日期 (格式: YYYY-MM-DD)":               "end date of the custom period (format: YYYY-MM-DD)",
+		"归纳年报 (y)":             "Summarise yearly report (y)",
+		"请选择要生成的报告类型：":         "Select the report type to generate:",
+		"选择报告类型失败：%v":          "failed to select report type: %v",
+		"未找到可用的时间段":            "no periods available",
+		"请选择时间段（空格键选择，回车键确认）：": "Select periods (space to toggle, enter to confirm):",
+		"选择时间段失败：%v":           "failed to select periods: %v",
+		"  %s：%s":              "  %s: %s",
+		"输出格式 (md, csv, json)": "output format (md, csv, json)",
+		"监听地址":                 "listen address",
+		"请在浏览器中打开 http://%s/":  "Open http://%s/ in your browser",
+		"未发现问题":                "No problems found",
+		"共发现 %d 个问题":           "%d problems found",
+		"未被覆盖的日报":              "Uncovered daily notes",
+		"跨月周报":                 "Weekly reports spanning two months",
+		"重复计入":                 "Counted more than once",
+		"过期的报告":                "Stale reports",
+		"统计数据不一致":              "Counter mismatches",
+		"未选择任何时间段":             "no period selected",
+	},
+}
+
+// ParseLocale 将 zh_CN.UTF-8、en_US 等写法规范为支持的语言，无法识别时返回简体中文
+func ParseLocale(value string) Locale {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	if value == "en" || strings.HasPrefix(value, "en_") || strings.HasPrefix(value, "en-") {
+		return LocaleEn
+	}
+	return LocaleZhCN
+}
+
+// DetectLocale 按 LC_ALL、LC_MESSAGES、LANG 的顺序从环境变量中检测界面语言
+func DetectLocale() Locale {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(key); value != "" && value != "C" && value != "POSIX" {
+			return ParseLocale(value)
+		}
+	}
+	return LocaleZhCN
+}
+
+// T 翻译一条消息
+func (l Locale) T(msg string) string {
+	if translated, ok := catalogs[l][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf 翻译格式字符串后再格式化
+func (l Locale) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(l.T(format), args...)
+}
+
+// Errorf 翻译格式字符串后生成错误
+func (l Locale) Errorf(format string, args ...interface{}) error {
+	if len(args) == 0 {
+		return errors.New(l.T(format))
+	}
+	return fmt.Errorf(l.T(format), args...)
+}
@@ -2,42 +2,17 @@ package reportgen
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
+	"time"
 )
 
-// extractFrontMatterStats 从周报的文档属性中提取查宿和特种工监考次数
-func (g *MonthlyGenerator) extractFrontMatterStats(content string) (int, int) {
-	var dormCount, examCount int
-
-	// 匹配YAML格式的文档属性
-	re := regexp.MustCompile(`(?m)^查宿次数: "(\d+)"$|^特种工监考: "(\d+)"$`)
-	matches := re.FindAllStringSubmatch(content, -1)
-
-	for _, match := range matches {
-		if match[1] != "" { // 查宿次数
-			if count, err := strconv.Atoi(match[1]); err == nil {
-				dormCount = count
-			}
-		} else if match[2] != "" { // 特种工监考
-			if count, err := strconv.Atoi(match[2]); err == nil {
-				examCount = count
-			}
-		}
-	}
-
-	return dormCount, examCount
-}
-
 // Generate 生成月报
 func (g *MonthlyGenerator) Generate(sourcePath string, params map[string]string) error {
 	// 读取周报文件
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return fmt.Errorf("读取周报文件失败：%v", err)
+		return g.Config.Locale.Errorf("读取周报文件失败：%v", err)
 	}
 
 	// 筛选指定月份的报告
@@ -54,7 +29,7 @@ func (g *MonthlyGenerator) Generate(sourcePath string, params map[string]string)
 	}
 
 	if len(selectedReports) == 0 {
-		return fmt.Errorf("未找到 %s 月的周报", g.Config.SelectedPeriod)
+		return g.Config.Locale.Errorf("未找到 %s 月的周报", g.Config.SelectedPeriod)
 	}
 
 	// 生成周报链接列表
@@ -66,15 +41,16 @@ func (g *MonthlyGenerator) Generate(sourcePath string, params map[string]string)
 
 	// 合并报告内容并格式化
 	content := g.mergeSectionsAndFormat(selectedReports)
+	terms := g.terms()
 
 	// 统计各项数据
-	listeningCount := countListeningClasses(content)
+	listeningCount := countListeningClasses(content, terms.Listening)
 	totalDormCount := 0
 	totalExamCount := 0
 
 	// 从每个周报的文档属性中统计查宿和特种工监考次数
 	for _, report := range selectedReports {
-		dormCount, examCount := g.extractFrontMatterStats(report.Content)
+		dormCount, examCount := g.reportStats(report, terms)
 		totalDormCount += dormCount
 		totalExamCount += examCount
 	}
@@ -82,41 +58,64 @@ func (g *MonthlyGenerator) Generate(sourcePath string, params map[string]string)
 	// 生成文档属性
 	var frontMatter strings.Builder
 	frontMatter.WriteString("---\n")
-	frontMatter.WriteString(fmt.Sprintf("听课次数: \"%d\"\n", listeningCount))
-	frontMatter.WriteString(fmt.Sprintf("查宿次数: \"%d\"\n", totalDormCount))
-	frontMatter.WriteString(fmt.Sprintf("特种工监考: \"%d\"\n", totalExamCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ListeningCountKey, listeningCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.DormCountKey, totalDormCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ExamCountKey, totalExamCount))
 	frontMatter.WriteString("---\n\n")
 
+	// 调用大模型生成总结
+	content, err = g.appendSummary(content)
+	if err != nil {
+		return err
+	}
+
 	// 在内容前添加周报链接和文档属性
 	content = frontMatter.String() + weeklyLinks.String() + content
 
 	// 生成输出文件名
-	outputFile := filepath.Join(g.Config.TargetDir, fmt.Sprintf("%s.md", g.Config.SelectedPeriod))
+	outputFile := filepath.Join(g.Config.TargetDir, monthlyOutputName(g.Config.SelectedPeriod))
 
 	// 写入文件
-	return os.WriteFile(outputFile, []byte(content), 0644)
+	start, _ := time.Parse("200601", g.Config.SelectedPeriod)
+	sidecar := newSidecar("m", g.Config.SelectedPeriod, start, start.AddDate(0, 1, -1), selectedReports)
+	sidecar.Counters = &SidecarCounters{Listening: listeningCount, Dorm: totalDormCount, Exam: totalExamCount}
+	return g.writeReport(outputFile, content, sidecar)
+}
+
+// monthlyOutputName 生成月报文件名
+func monthlyOutputName(month string) string {
+	return fmt.Sprintf("%s.md", month)
 }
 
 // GetAvailablePeriods 获取可用的月份
-func (g *MonthlyGenerator) GetAvailablePeriods(sourcePath string) ([]string, error) {
+func (g *MonthlyGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取周报文件失败：%v", err)
+		return nil, g.Config.Locale.Errorf("读取周报文件失败：%v", err)
 	}
 
-	monthMap := make(map[string]bool)
+	monthMap := make(map[string]*Period)
 	for _, report := range reports {
 		date, err := ExtractDateFromFilename(report.FilePath)
 		if err != nil {
 			continue
 		}
 
-		monthMap[date.Format("200601")] = true
+		month := date.Format("200601")
+		if _, ok := monthMap[month]; !ok {
+			start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+			monthMap[month] = &Period{
+				Kind:      "m",
+				Key:       month,
+				Label:     g.Config.Locale.Sprintf("%d 年 %d 月", date.Year(), int(date.Month())),
+				Target:    monthlyOutputName(month),
+				Generated: g.targetExists(monthlyOutputName(month)),
+			}
+			extendPeriod(monthMap[month], start, start.AddDate(0, 1, -1))
+			continue
+		}
+		monthMap[month].Sources++
 	}
 
-	var months []string
-	for month := range monthMap {
-		months = append(months, month)
-	}
-	return months, nil
+	return sortedPeriods(monthMap), nil
 }
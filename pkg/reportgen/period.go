@@ -0,0 +1,74 @@
+package reportgen
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Period 描述一个可生成报告的时间段
+type Period struct {
+	Kind      string    // 报告类型 (w: 周报, m: 月报, s: 学期报, y: 年报, c: 自定义时间段, a: 教学统计, d: 部门汇总)
+	Key       string    // 作为 Config.SelectedPeriod 使用的值
+	Label     string    // 显示名称，如“第 5 周”
+	Start     time.Time // 起始日期
+	End       time.Time // 结束日期
+	Sources   int       // 源文件数量
+	Target    string    // 目标报告文件名
+	Generated bool      // 目标报告是否已存在
+}
+
+// sourceNouns 各类报告的源文件名称
+var sourceNouns = map[string]string{
+	"w": "%d 篇日报",
+	"m": "%d 篇周报",
+	"s": "%d 篇月报",
+	"y": "%d 篇学期报",
+	"c": "%d 篇日报",
+	"a": "%d 篇日报",
+	"d": "%d 位教师",
+}
+
+// Describe 返回用于选择列表的描述，如“第 5 周 (2024-09-30 – 2024-10-04, 5 篇日报, 已生成)”
+func (p Period) Describe(locale Locale) string {
+	noun, ok := sourceNouns[p.Kind]
+	if !ok {
+		noun = "%d 个源文件"
+	}
+	desc := fmt.Sprintf("%s (%s – %s, %s", p.Label, p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"), locale.Sprintf(noun, p.Sources))
+	if p.Generated {
+		desc += ", " + locale.T("已生成")
+	}
+	return desc + ")"
+}
+
+// SortPeriods 按时间先后对时间段排序
+func SortPeriods(periods []Period) {
+	sort.SliceStable(periods, func(i, j int) bool {
+		if !periods[i].Start.Equal(periods[j].Start) {
+			return periods[i].Start.Before(periods[j].Start)
+		}
+		return periods[i].Key < periods[j].Key
+	})
+}
+
+// extendPeriod 将日期并入时间段的起止范围，并累计源文件数量
+func extendPeriod(period *Period, start, end time.Time) {
+	if period.Sources == 0 || start.Before(period.Start) {
+		period.Start = start
+	}
+	if period.Sources == 0 || end.After(period.End) {
+		period.End = end
+	}
+	period.Sources++
+}
+
+// sortedPeriods 将按键收集的时间段转换为有序切片
+func sortedPeriods(periodMap map[string]*Period) []Period {
+	periods := make([]Period, 0, len(periodMap))
+	for _, period := range periodMap {
+		periods = append(periods, *period)
+	}
+	SortPeriods(periods)
+	return periods
+}
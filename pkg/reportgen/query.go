@@ -0,0 +1,387 @@
+package reportgen
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Entry 日报中的一条记录：一个三级标题块、三级标题下的一个四级标题块（如听课中的一节课），
+// 或没有三级标题的部分中的一行
+type Entry struct {
+	Date    time.Time `json:"date"`
+	File    string    `json:"file"`
+	Section string    `json:"section"`
+	Course  string    `json:"course,omitempty"` // 三级标题中 [[链接]] 指向的课程或教师
+	Tags    []string  `json:"tags,omitempty"`
+	Text    string    `json:"text"`
+}
+
+// MarshalJSON 以 YYYY-MM-DD 格式输出日期
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		entry
+		Date string `json:"date"`
+	}{entry(e), e.Date.Format("2006-01-02")})
+}
+
+var (
+	entryLinkRegex = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+	entryTagRegex  = regexp.MustCompile(`#[^\s#]+`)
+)
+
+// extractEntries 将一篇日报拆分为记录
+func extractEntries(report Report, date time.Time) []Entry {
+	var entries []Entry
+	var current *Entry
+	var parent Entry // 当前的三级标题，其下的四级标题块继承其课程、标签和标题
+	inBlock := false // 是否位于三级标题块中
+	bare := false    // current 是否仍只有三级标题，没有正文和四级标题
+	section := ""
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(current.Text)
+			if current.Text != "" || current.Course != "" {
+				entries = append(entries, *current)
+			}
+			current = nil
+		}
+	}
+
+	inFrontMatter := false
+	for i, line := range strings.Split(report.Content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && trimmed == "---" {
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			inFrontMatter = trimmed != "---"
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "## "):
+			flush()
+			section = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			inBlock = false
+		case section == "":
+			continue
+		case strings.HasPrefix(trimmed, "### "):
+			flush()
+			heading := strings.TrimSpace(strings.TrimPrefix(trimmed, "### "))
+			current = &Entry{Date: date, File: report.FilePath, Section: section}
+			if match := entryLinkRegex.FindStringSubmatch(heading); match != nil {
+				current.Course = match[1]
+				heading = strings.Replace(heading, match[0], "", 1)
+			}
+			current.Tags = entryTagRegex.FindAllString(heading, -1)
+			current.Text = strings.TrimSpace(entryTagRegex.ReplaceAllString(heading, ""))
+			parent, inBlock, bare = *current, true, true
+		case strings.HasPrefix(trimmed, "#### ") && inBlock:
+			title := strings.TrimSpace(strings.TrimPrefix(trimmed, "#### "))
+			tags := entryTagRegex.FindAllString(title, -1)
+			title = strings.TrimSpace(entryTagRegex.ReplaceAllString(title, ""))
+			if title == "无" {
+				// “无”表示没有内容，不成为记录，只有标题的三级标题块保留为一条记录
+				if !bare {
+					flush()
+				}
+				continue
+			}
+			// 只有标题的三级标题块由其下的各个四级标题块代替
+			if bare {
+				current = nil
+			}
+			flush()
+			child := parent
+			child.Tags = append(append([]string(nil), parent.Tags...), tags...)
+			if title != "" {
+				child.Text = strings.TrimSpace(child.Text + "\n" + title)
+			}
+			current, bare = &child, false
+		case current != nil:
+			if trimmed != "" {
+				current.Text += "\n" + trimmed
+				bare = false
+			}
+		case inBlock:
+			// “#### 无”之后的内容不成为记录
+			continue
+		case trimmed != "" && trimmed != "无":
+			entries = append(entries, Entry{Date: date, File: report.FilePath, Section: section, Text: trimmed})
+		}
+	}
+	flush()
+	return entries
+}
+
+// CollectEntries 读取日报目录下所有日报中的记录，按日期排序，locale 用于提示信息
+func CollectEntries(dailyDir string, locale Locale) ([]Entry, error) {
+	g := &BaseGenerator{Config: &Config{Locale: locale}}
+	reports, err := g.readFiles(dailyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, report := range reports {
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, extractEntries(report, date)...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+// queryTerm 查询语句中的一个条件
+type queryTerm struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// Query 记录查询条件，所有条件需同时满足
+type Query struct {
+	terms []queryTerm
+	from  time.Time
+	to    time.Time
+}
+
+// queryKeys 支持的条件名及其别名
+var queryKeys = map[string]string{
+	"section":  "section",
+	"s":        "section",
+	"部分":       "section",
+	"course":   "course",
+	"c":        "course",
+	"link":     "course",
+	"课程":       "course",
+	"tag":      "tag",
+	"t":        "tag",
+	"标签":       "tag",
+	"text":     "text",
+	"内容":       "text",
+	"from":     "from",
+	"to":       "to",
+	"date":     "date",
+	"日期":       "date",
+	"semester": "semester",
+	"term":     "semester",
+	"学期":       "semester",
+}
+
+// tokenizeQuery 按空白拆分查询语句，双引号内的空白不拆分
+func tokenizeQuery(expr string, locale Locale) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuote := false
+	hasToken := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasToken = true
+		case unicode.IsSpace(r) && !inQuote:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if inQuote {
+		return nil, locale.Errorf("引号未闭合")
+	}
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// ParseQuery 解析查询语句，如 `section:教学 course:机械制图 tag:#实训 from:2024-09-01 to:2025-01-31 三视图`。
+// 不带条件名的词按内容匹配，条件名前加 - 表示排除，日期范围条件不能排除
+func ParseQuery(expr string, locale Locale) (*Query, error) {
+	tokens, err := tokenizeQuery(expr, locale)
+	if err != nil {
+		return nil, locale.Errorf("查询语句有误：%v", err)
+	}
+	return parseTerms(tokens, locale)
+}
+
+// ParseQueryArgs 解析命令行参数中的查询条件。shell 已去除引号，每个参数即一个条件，不再按空白拆分
+func ParseQueryArgs(args []string, locale Locale) (*Query, error) {
+	return parseTerms(args, locale)
+}
+
+// parseTerms 解析已拆分的各个条件
+func parseTerms(tokens []string, locale Locale) (*Query, error) {
+	query := &Query{}
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		term := queryTerm{key: "text", value: token}
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			term.negate = true
+			token = token[1:]
+			term.value = token
+		}
+		if key, value, ok := strings.Cut(token, ":"); ok {
+			name, known := queryKeys[strings.ToLower(key)]
+			if !known {
+				return nil, locale.Errorf("不支持的查询条件：%s", key)
+			}
+			term.key = name
+			term.value = value
+		}
+
+		switch term.key {
+		case "from", "to", "date", "semester":
+			if term.negate {
+				return nil, locale.Errorf("查询语句有误：%s 条件不支持排除", term.key)
+			}
+		}
+
+		switch term.key {
+		case "from", "to", "date":
+			date, err := parseRangeDate(term.value, locale)
+			if err != nil {
+				return nil, locale.Errorf("查询语句有误：%v", err)
+			}
+			if term.key != "to" {
+				query.from = date
+			}
+			if term.key != "from" {
+				query.to = date
+			}
+		case "semester":
+			// 学期中含空格，未加引号时被拆开，与其后不含条件名的词合并
+			start, end, err := SemesterRange(term.value)
+			for j := i + 1; err != nil && j < len(tokens) && j <= i+3 && !strings.Contains(tokens[j], ":"); j++ {
+				if start, end, err = SemesterRange(strings.Join(append([]string{term.value}, tokens[i+1:j+1]...), " ")); err == nil {
+					i = j
+				}
+			}
+			if err != nil {
+				return nil, locale.Errorf("查询语句有误：%v", err)
+			}
+			query.from, query.to = start, end
+		case "tag":
+			term.value = "#" + strings.TrimPrefix(term.value, "#")
+			query.terms = append(query.terms, term)
+		default:
+			query.terms = append(query.terms, term)
+		}
+	}
+	return query, nil
+}
+
+// Match 判断记录是否满足查询条件
+func (q *Query) Match(entry Entry) bool {
+	if !q.from.IsZero() && entry.Date.Before(q.from) {
+		return false
+	}
+	if !q.to.IsZero() && entry.Date.After(q.to) {
+		return false
+	}
+
+	for _, term := range q.terms {
+		var matched bool
+		switch term.key {
+		case "section":
+			matched = entry.Section == term.value
+		case "course":
+			matched = strings.Contains(entry.Course, term.value)
+		case "tag":
+			for _, tag := range entry.Tags {
+				if tag == term.value {
+					matched = true
+					break
+				}
+			}
+		default:
+			matched = strings.Contains(entry.Text, term.value) || strings.Contains(entry.Course, term.value)
+		}
+		if matched == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter 返回满足查询条件的记录
+func (q *Query) Filter(entries []Entry) []Entry {
+	var matched []Entry
+	for _, entry := range entries {
+		if q.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// WriteEntries 以 Markdown 表格、CSV 或 JSON 格式输出记录
+func WriteEntries(w io.Writer, entries []Entry, format string, locale Locale) error {
+	switch format {
+	case "json":
+		if entries == nil {
+			entries = []Entry{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(entries)
+
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"date", "file", "section", "course", "tags", "text"})
+		for _, entry := range entries {
+			writer.Write([]string{
+				entry.Date.Format("2006-01-02"),
+				entry.File,
+				entry.Section,
+				entry.Course,
+				strings.Join(entry.Tags, " "),
+				entry.Text,
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case "md", "markdown", "":
+		cell := strings.NewReplacer("|", "\\|", "\n", "<br>")
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n", locale.T("日期"), locale.T("部分"), locale.T("课程"), locale.T("标签"), locale.T("内容"), locale.T("来源"))
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- | --- |")
+		for _, entry := range entries {
+			course := ""
+			if entry.Course != "" {
+				course = fmt.Sprintf("[[%s]]", entry.Course)
+			}
+			source := strings.TrimSuffix(filepath.Base(entry.File), filepath.Ext(entry.File))
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | [[%s]] |\n",
+				entry.Date.Format("2006-01-02"),
+				cell.Replace(entry.Section),
+				cell.Replace(course),
+				cell.Replace(strings.Join(entry.Tags, " ")),
+				cell.Replace(entry.Text),
+				source)
+		}
+		return nil
+	}
+	return locale.Errorf("不支持的输出格式：%s", format)
+}
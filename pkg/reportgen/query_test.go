@@ -0,0 +1,91 @@
+package reportgen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQueryArgs(t *testing.T) {
+	autumnStart := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)
+	autumnEnd := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		args  []string
+		terms int
+	}{
+		// reportgen query semester:"2024 - 2025 秋"，shell 去除引号后只剩一个参数
+		{"学期参数", []string{"semester:2024 - 2025 秋"}, 0},
+		{"学期与内容", []string{"semester:2024 - 2025 秋", "三视图"}, 1},
+		{"未加引号的学期", []string{"semester:2024", "-", "2025", "秋", "三视图"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQueryArgs(tt.args, LocaleZhCN)
+			if err != nil {
+				t.Fatalf("ParseQueryArgs(%q) error = %v", tt.args, err)
+			}
+			if !query.from.Equal(autumnStart) || !query.to.Equal(autumnEnd) {
+				t.Errorf("ParseQueryArgs(%q) 日期范围 = %s 至 %s", tt.args, query.from.Format("2006-01-02"), query.to.Format("2006-01-02"))
+			}
+			if len(query.terms) != tt.terms {
+				t.Errorf("ParseQueryArgs(%q) 条件数 = %d, want %d", tt.args, len(query.terms), tt.terms)
+			}
+		})
+	}
+}
+
+func TestParseQueryArgsKeepsSpaces(t *testing.T) {
+	for _, args := range [][]string{{"机械 制图"}, {"section:教学", "机械 制图"}} {
+		query, err := ParseQueryArgs(args, LocaleZhCN)
+		if err != nil {
+			t.Fatalf("ParseQueryArgs(%q) error = %v", args, err)
+		}
+		if last := query.terms[len(query.terms)-1]; len(query.terms) != len(args) || last.value != "机械 制图" {
+			t.Errorf("ParseQueryArgs(%q) terms = %+v，每个参数应为一个条件", args, query.terms)
+		}
+	}
+}
+
+func TestParseQueryRejectsNegatedRange(t *testing.T) {
+	for _, expr := range []string{"-from:2024-09-01", "-to:2024-09-01", "-date:2024-09-01", `-semester:"2024 - 2025 秋"`} {
+		if _, err := ParseQuery(expr, LocaleZhCN); err == nil {
+			t.Errorf("ParseQuery(%q) 应返回错误", expr)
+		}
+	}
+}
+
+func TestExtractEntriesListening(t *testing.T) {
+	report := Report{FilePath: "20240930.md", Content: `## 听课
+
+### [[张老师]] 数控编程 #公开课
+#### 数控车床编程 #示范
+重点讲解 G 代码
+#### 无
+
+### [[李老师]] 钳工
+评课记录
+#### 锉削
+
+### [[王老师]] 电工
+#### 无
+`}
+	date := time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC)
+
+	want := []Entry{
+		{Course: "张老师", Tags: []string{"#公开课", "#示范"}, Text: "数控编程\n数控车床编程\n重点讲解 G 代码"},
+		{Course: "李老师", Text: "钳工\n评课记录"},
+		{Course: "李老师", Text: "钳工\n锉削"},
+		{Course: "王老师", Text: "电工"},
+	}
+	got := extractEntries(report, date)
+	if len(got) != len(want) {
+		t.Fatalf("extractEntries() 返回 %d 条记录, want %d: %+v", len(got), len(want), got)
+	}
+	for i, entry := range got {
+		if entry.Section != "听课" || entry.Course != want[i].Course || entry.Text != want[i].Text || strings.Join(entry.Tags, " ") != strings.Join(want[i].Tags, " ") {
+			t.Errorf("extractEntries()[%d] = %+v, want %+v", i, entry, want[i])
+		}
+	}
+}
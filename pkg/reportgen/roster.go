@@ -0,0 +1,304 @@
+package reportgen
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// RosterEntry 学校排班表中的一项任务
+type RosterEntry struct {
+	Date    time.Time
+	Teacher string // 排班表中的教师一栏，可能包含多位教师
+	Duty    string // 任务名称，如“查宿”“监考”
+	Row     int    // 在排班表中的行号，从 1 开始
+}
+
+// rosterColumns 排班表表头中各列可能使用的名称
+var rosterColumns = map[string][]string{
+	"date":    {"日期", "date"},
+	"teacher": {"教师", "姓名", "人员", "值班人", "teacher", "name"},
+	"duty":    {"任务", "类型", "项目", "内容", "duty", "type"},
+}
+
+// LoadRoster 读取 XLSX 或 CSV 格式的排班表，sheet 为空时读取第一个工作表。
+// 表头需要包含日期、教师和任务三列；日期为空的行沿用上一行的日期，以兼容合并单元格
+func LoadRoster(path, sheet string, locale Locale) ([]RosterEntry, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".xlsm":
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, locale.Errorf("打开排班表失败：%v", err)
+		}
+		defer f.Close()
+		if sheet == "" {
+			sheet = f.GetSheetName(0)
+		}
+		// 读取原始值，日期单元格为序列号，不受单元格格式影响
+		if rows, err = f.GetRows(sheet, excelize.Options{RawCellValue: true}); err != nil {
+			return nil, locale.Errorf("读取工作表 %s 失败：%v", sheet, err)
+		}
+	case ".csv":
+		text, err := readNote(path, locale)
+		if err != nil {
+			return nil, locale.Errorf("打开排班表失败：%v", err)
+		}
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.FieldsPerRecord = -1
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, locale.Errorf("读取排班表失败：%v", err)
+			}
+			rows = append(rows, record)
+		}
+	default:
+		return nil, locale.Errorf("不支持的排班表格式：%s", filepath.Ext(path))
+	}
+
+	// 查找表头
+	header, columns := -1, map[string]int{}
+	for i, row := range rows {
+		found := map[string]int{}
+		for j, cell := range row {
+			cell = strings.ToLower(strings.TrimSpace(cell))
+			for column, names := range rosterColumns {
+				for _, name := range names {
+					if _, ok := found[column]; !ok && cell == name {
+						found[column] = j
+					}
+				}
+			}
+		}
+		if len(found) == len(rosterColumns) {
+			header, columns = i, found
+			break
+		}
+	}
+	if header < 0 {
+		return nil, locale.Errorf("排班表中未找到日期、教师和任务三列")
+	}
+
+	cell := func(row []string, column string) string {
+		if j := columns[column]; j < len(row) {
+			return strings.TrimSpace(row[j])
+		}
+		return ""
+	}
+
+	var entries []RosterEntry
+	var lastDate time.Time
+	for i, row := range rows[header+1:] {
+		date := lastDate
+		if value := cell(row, "date"); value != "" {
+			parsed, err := parseRosterDate(value, locale)
+			if err != nil {
+				return nil, locale.Errorf("排班表第 %d 行：%v", header+i+2, err)
+			}
+			date, lastDate = parsed, parsed
+		}
+		teacher, duty := cell(row, "teacher"), cell(row, "duty")
+		if date.IsZero() || teacher == "" || duty == "" {
+			continue
+		}
+		entries = append(entries, RosterEntry{Date: date, Teacher: teacher, Duty: duty, Row: header + i + 2})
+	}
+	return entries, nil
+}
+
+// rosterDateLayouts 排班表中常见的文本日期格式
+var rosterDateLayouts = []string{"2006-1-2", "2006/1/2", "2006.1.2", "2006年1月2日", "20060102"}
+
+// parseRosterDate 解析排班表中的日期，支持文本日期和 Excel 日期序列号
+func parseRosterDate(value string, locale Locale) (time.Time, error) {
+	for _, layout := range rosterDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		date, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, locale.Errorf("无法解析日期 %s", value)
+}
+
+// 核对排班表发现的问题类型
+const (
+	RosterMissed  = "missed"  // 排班表中有，日报中没有
+	RosterExtra   = "extra"   // 日报中有，排班表中没有
+	RosterSwapped = "swapped" // 日报中的日期与排班表不同，视为调班
+)
+
+// RosterIssue 核对排班表发现的一个问题
+type RosterIssue struct {
+	Kind       string
+	Duty       string    // 任务类型，取自词汇中的关键词
+	Scheduled  time.Time // 排班日期，多出的任务为零值
+	Actual     time.Time // 日报日期，遗漏的任务为零值
+	RosterRow  int
+	File       string
+	RosterText string
+	NoteText   string
+}
+
+// SwapWindow 遗漏与多出的同类任务相差不超过该天数时视为调班
+const SwapWindow = 7
+
+// dutyType 返回文本对应的任务类型，即其中包含的第一个值守关键词
+func (t *Terms) dutyType(text string) string {
+	for _, keyword := range t.dutyKeywords() {
+		if strings.Contains(text, keyword) {
+			return keyword
+		}
+	}
+	return text
+}
+
+// hasTeacher 判断排班表的教师单元格中是否有指定教师，多位教师以顿号、逗号、斜杠、分号或空白分隔，姓名须完全相同
+func hasTeacher(cell, teacher string) bool {
+	teacher = strings.TrimSpace(teacher)
+	for _, name := range strings.FieldsFunc(cell, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("、，,/／;；", r)
+	}) {
+		if name == teacher {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileRoster 核对排班表中指定教师在 [start, end] 内的任务与日报中记录的任务
+func ReconcileRoster(roster []RosterEntry, duties []Duty, teacher string, start, end time.Time, terms *Terms) []RosterIssue {
+	if terms == nil {
+		terms = DefaultTerms()
+	}
+	inRange := func(date time.Time) bool {
+		return !date.Before(start) && !date.After(end)
+	}
+
+	var scheduled []RosterEntry
+	for _, entry := range roster {
+		if inRange(entry.Date) && hasTeacher(entry.Teacher, teacher) {
+			scheduled = append(scheduled, entry)
+		}
+	}
+	var recorded []Duty
+	for _, duty := range duties {
+		if inRange(duty.Date) {
+			recorded = append(recorded, duty)
+		}
+	}
+
+	// 先按日期和任务类型精确匹配
+	used := make([]bool, len(recorded))
+	var missed []RosterEntry
+	for _, entry := range scheduled {
+		kind := terms.dutyType(entry.Duty)
+		matched := false
+		for i, duty := range recorded {
+			if !used[i] && duty.Date.Equal(entry.Date) && duty.Keyword == kind {
+				used[i], matched = true, true
+				break
+			}
+		}
+		if !matched {
+			missed = append(missed, entry)
+		}
+	}
+
+	// 再将遗漏的任务与日期最接近的同类多出任务配对为调班
+	var issues []RosterIssue
+	for _, entry := range missed {
+		kind := terms.dutyType(entry.Duty)
+		best := -1
+		for i, duty := range recorded {
+			if used[i] || duty.Keyword != kind {
+				continue
+			}
+			gap := absDays(duty.Date.Sub(entry.Date))
+			if gap <= SwapWindow && (best < 0 || gap < absDays(recorded[best].Date.Sub(entry.Date))) {
+				best = i
+			}
+		}
+		issue := RosterIssue{Kind: RosterMissed, Duty: kind, Scheduled: entry.Date, RosterRow: entry.Row, RosterText: entry.Duty}
+		if best >= 0 {
+			used[best] = true
+			issue.Kind = RosterSwapped
+			issue.Actual = recorded[best].Date
+			issue.File = recorded[best].File
+			issue.NoteText = recorded[best].Text
+		}
+		issues = append(issues, issue)
+	}
+
+	for i, duty := range recorded {
+		if !used[i] {
+			issues = append(issues, RosterIssue{Kind: RosterExtra, Duty: duty.Keyword, Actual: duty.Date, File: duty.File, NoteText: duty.Text})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].date().Before(issues[j].date())
+	})
+	return issues
+}
+
+// date 返回用于排序的日期
+func (i RosterIssue) date() time.Time {
+	if !i.Scheduled.IsZero() {
+		return i.Scheduled
+	}
+	return i.Actual
+}
+
+// absDays 返回时间差的绝对天数
+func absDays(d time.Duration) int {
+	days := int(d.Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// WriteRosterIssues 以 Markdown 表格输出核对结果
+func WriteRosterIssues(w io.Writer, issues []RosterIssue, locale Locale) {
+	kinds := map[string]string{
+		RosterMissed:  "遗漏",
+		RosterExtra:   "排班表外",
+		RosterSwapped: "调班",
+	}
+	format := func(date time.Time) string {
+		if date.IsZero() {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+
+	fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", locale.T("问题"), locale.T("任务"), locale.T("排班日期"), locale.T("日报日期"), locale.T("来源"))
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+	for _, issue := range issues {
+		var sources []string
+		if issue.RosterRow > 0 {
+			sources = append(sources, locale.Sprintf("排班表第 %d 行", issue.RosterRow))
+		}
+		if issue.File != "" {
+			sources = append(sources, fmt.Sprintf("[[%s]]", strings.TrimSuffix(filepath.Base(issue.File), filepath.Ext(issue.File))))
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", locale.T(kinds[issue.Kind]), issue.Duty, format(issue.Scheduled), format(issue.Actual), strings.Join(sources, "<br>"))
+	}
+}
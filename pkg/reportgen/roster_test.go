@@ -0,0 +1,26 @@
+package reportgen
+
+import "testing"
+
+func TestHasTeacher(t *testing.T) {
+	tests := []struct {
+		cell    string
+		teacher string
+		want    bool
+	}{
+		{"王伟", "王伟", true},
+		{" 王伟 ", "王伟", true},
+		{"王伟华", "王伟", false},
+		{"王伟华、李明", "王伟", false},
+		{"李明、王伟", "王伟", true},
+		{"李明, 王伟", "王伟", true},
+		{"李明/王伟", "王伟", true},
+		{"李明 王伟", "王伟", true},
+		{"", "王伟", false},
+	}
+	for _, tt := range tests {
+		if got := hasTeacher(tt.cell, tt.teacher); got != tt.want {
+			t.Errorf("hasTeacher(%q, %q) = %v, want %v", tt.cell, tt.teacher, got, tt.want)
+		}
+	}
+}
@@ -2,20 +2,18 @@ package reportgen
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 )
 
-// extractFrontMatterStats 从月报的文档属性中提取查宿和特种工监考次数
-func extractFrontMatterStats(content string) (int, int) {
+// extractFrontMatterStats 从周报或月报的文档属性中提取查宿和特种工监考次数
+func extractFrontMatterStats(content string, terms *Terms) (int, int) {
 	var dormCount, examCount int
 
 	// 匹配YAML格式的文档属性
-	re := regexp.MustCompile(`(?m)^查宿次数: "(\d+)"$|^特种工监考: "(\d+)"$`)
+	re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(terms.DormCountKey) + `: "(\d+)"$|^` + regexp.QuoteMeta(terms.ExamCountKey) + `: "(\d+)"$`)
 	matches := re.FindAllStringSubmatch(content, -1)
 
 	for _, match := range matches {
@@ -38,7 +36,7 @@ func (g *SemesterGenerator) Generate(sourcePath string, params map[string]string
 	// 读取月报文件
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return fmt.Errorf("读取月报文件失败：%v", err)
+		return g.Config.Locale.Errorf("读取月报文件失败：%v", err)
 	}
 
 	// 筛选指定学期的报告
@@ -55,7 +53,7 @@ func (g *SemesterGenerator) Generate(sourcePath string, params map[string]string
 	}
 
 	if len(selectedReports) == 0 {
-		return fmt.Errorf("未找到 %s 学期的月报", g.Config.SelectedPeriod)
+		return g.Config.Locale.Errorf("未找到 %s 学期的月报", g.Config.SelectedPeriod)
 	}
 
 	// 生成月报链接列表
@@ -67,15 +65,16 @@ func (g *SemesterGenerator) Generate(sourcePath string, params map[string]string
 
 	// 合并报告内容并格式化
 	content := g.mergeSectionsAndFormat(selectedReports)
+	terms := g.terms()
 
 	// 统计各项数据
-	listeningCount := countListeningClasses(content)
+	listeningCount := countListeningClasses(content, terms.Listening)
 	totalDormCount := 0
 	totalExamCount := 0
 
 	// 从每个月报的文档属性中统计查宿和特种工监考次数
 	for _, report := range selectedReports {
-		dormCount, examCount := extractFrontMatterStats(report.Content)
+		dormCount, examCount := g.reportStats(report, terms)
 		totalDormCount += dormCount
 		totalExamCount += examCount
 	}
@@ -83,44 +82,62 @@ func (g *SemesterGenerator) Generate(sourcePath string, params map[string]string
 	// 生成文档属性
 	var frontMatter strings.Builder
 	frontMatter.WriteString("---\n")
-	frontMatter.WriteString(fmt.Sprintf("听课次数: \"%d\"\n", listeningCount))
-	frontMatter.WriteString(fmt.Sprintf("查宿次数: \"%d\"\n", totalDormCount))
-	frontMatter.WriteString(fmt.Sprintf("特种工监考: \"%d\"\n", totalExamCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ListeningCountKey, listeningCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.DormCountKey, totalDormCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ExamCountKey, totalExamCount))
 	frontMatter.WriteString("---\n\n")
 
+	// 调用大模型生成总结
+	content, err = g.appendSummary(content)
+	if err != nil {
+		return err
+	}
+
 	// 在内容前添加月报链接和文档属性
 	content = frontMatter.String() + monthlyLinks.String() + content
 
 	// 生成输出文件名
-	outputFile := filepath.Join(g.Config.TargetDir, fmt.Sprintf("%s流水账.md", g.Config.SelectedPeriod))
+	outputFile := filepath.Join(g.Config.TargetDir, g.terms().SemesterFile(g.Config.SelectedPeriod))
 
 	// 写入文件
-	return os.WriteFile(outputFile, []byte(content), 0644)
+	start, end, _ := SemesterRange(g.Config.SelectedPeriod)
+	sidecar := newSidecar("s", g.Config.SelectedPeriod, start, end, selectedReports)
+	sidecar.Counters = &SidecarCounters{Listening: listeningCount, Dorm: totalDormCount, Exam: totalExamCount}
+	return g.writeReport(outputFile, content, sidecar)
 }
 
 // GetAvailablePeriods 获取可用的学期
-func (g *SemesterGenerator) GetAvailablePeriods(sourcePath string) ([]string, error) {
+func (g *SemesterGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取月报文件失败：%v", err)
+		return nil, g.Config.Locale.Errorf("读取月报文件失败：%v", err)
 	}
 
-	semesterMap := make(map[string]bool)
+	semesterMap := make(map[string]*Period)
 	for _, report := range reports {
 		date, err := ExtractMonthFromFilename(report.FilePath)
 		if err != nil {
 			continue
 		}
 
-		semesterMap[GetSemesterPeriod(date)] = true
-	}
-
-	var semesters []string
-	for semester := range semesterMap {
-		semesters = append(semesters, semester)
+		semester := GetSemesterPeriod(date)
+		if _, ok := semesterMap[semester]; !ok {
+			start, end, err := SemesterRange(semester)
+			if err != nil {
+				continue
+			}
+			semesterMap[semester] = &Period{
+				Kind:      "s",
+				Key:       semester,
+				Label:     semester,
+				Target:    g.terms().SemesterFile(semester),
+				Generated: g.targetExists(g.terms().SemesterFile(semester)),
+			}
+			extendPeriod(semesterMap[semester], start, end)
+			continue
+		}
+		semesterMap[semester].Sources++
 	}
 
-	// 对学期进行排序
-	sort.Strings(semesters)
-	return semesters, nil
+	return sortedPeriods(semesterMap), nil
 }
@@ -0,0 +1,354 @@
+package reportgen
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Server 提供浏览和生成报告的本地网页界面
+type Server struct {
+	base Config     // 各级报告共用的配置，SourceDir、TargetDir 等按请求填充
+	host string     // 监听地址中的主机名，用于拒绝 DNS 重绑定的请求
+	mu   sync.Mutex // 同一时间只生成一份报告
+	tmpl *template.Template
+}
+
+// serverLevels 网页中列出的报告类型及其标题
+var serverLevels = []struct {
+	Kind  string
+	Title string
+}{
+	{"w", "周报"},
+	{"m", "月报"},
+	{"s", "学期报"},
+	{"y", "年报"},
+	{"c", "自定义时间段"},
+	{"a", "教学统计"},
+}
+
+// serverLevel 首页中的一类报告
+type serverLevel struct {
+	Kind    string
+	Title   string
+	Periods []Period
+	Error   string
+}
+
+// NewServer 创建网页界面，base 中需要设置 WorkDir，其余字段用于生成报告，addr 为监听地址
+func NewServer(base Config, addr string) *Server {
+	if base.Terms == nil {
+		base.Terms = DefaultTerms()
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	lang := base.Locale
+	if lang == "" {
+		lang = LocaleZhCN
+	}
+	s := &Server{base: base, host: host}
+	s.tmpl = template.Must(template.New("page").Funcs(template.FuncMap{
+		"T":        base.Locale.T,
+		"lang":     func() string { return string(lang) },
+		"describe": func(p Period) string { return p.Describe(base.Locale) },
+		"query":    url.QueryEscape,
+	}).Parse(serverTemplate))
+	return s
+}
+
+// config 返回指定报告类型的配置
+func (s *Server) config(reportType, period string) (*Config, bool) {
+	source, target, ok := s.base.Terms.LevelDirs(reportType)
+	if !ok {
+		return nil, false
+	}
+	config := s.base
+	config.ReportType = reportType
+	config.SelectedPeriod = period
+	config.SourceDir = filepath.Join(s.base.WorkDir, source)
+	config.TargetDir = filepath.Join(s.base.WorkDir, target)
+	config.Backup = s.base.Backup.next() // 每次生成单独备份，可用 reportgen undo 撤销
+	return &config, true
+}
+
+// findPeriod 查找指定报告类型下的时间段
+func (s *Server) findPeriod(reportType, key string) (*Config, *Period, error) {
+	config, ok := s.config(reportType, key)
+	if !ok {
+		return nil, nil, s.base.Locale.Errorf("不支持的报告类型：%s", reportType)
+	}
+	generator, err := NewGenerator(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	periods, err := generator.GetAvailablePeriods(config.SourceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range periods {
+		if periods[i].Key == key {
+			return config, &periods[i], nil
+		}
+	}
+	return nil, nil, s.base.Locale.Errorf("未找到时间段 %s", key)
+}
+
+// allowedHost 判断请求的 Host 是否指向本服务：IP 地址、localhost 或监听地址中的主机名。
+// 其他域名可能是解析到本机的 DNS 重绑定攻击
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	return net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") || (s.host != "" && strings.EqualFold(host, s.host))
+}
+
+// sameOrigin 判断请求是否来自本服务的页面，浏览器跨站提交表单时 Origin 或 Referer 指向其他站点。
+// 两者均缺失时视为非浏览器客户端，予以放行
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		http.Error(w, s.base.Locale.Sprintf("不允许的主机名：%s", r.Host), http.StatusForbidden)
+		return
+	}
+	switch r.URL.Path {
+	case "/":
+		s.handleIndex(w, r)
+	case "/report":
+		s.handleReport(w, r)
+	case "/generate":
+		s.handleGenerate(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleIndex 按报告类型列出所有可用时间段
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	var levels []serverLevel
+	for _, level := range serverLevels {
+		config, _ := s.config(level.Kind, "")
+		item := serverLevel{Kind: level.Kind, Title: level.Title}
+		if _, err := os.Stat(config.SourceDir); err != nil {
+			continue
+		}
+		generator, err := NewGenerator(config)
+		if err == nil {
+			item.Periods, err = generator.GetAvailablePeriods(config.SourceDir)
+		}
+		if err != nil {
+			item.Error = err.Error()
+		}
+		levels = append(levels, item)
+	}
+	s.render(w, "index", map[string]interface{}{"Levels": levels})
+}
+
+// handleReport 预览一份报告及其统计数据
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	reportType := r.URL.Query().Get("t")
+	config, period, err := s.findPeriod(reportType, r.URL.Query().Get("p"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Kind":   reportType,
+		"Period": period,
+		"Error":  r.URL.Query().Get("error"),
+	}
+	if period.Generated {
+		content, err := readNote(filepath.Join(config.TargetDir, period.Target), config.Locale)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fields, body := SplitFrontMatter(content)
+		data["Fields"] = fields
+		data["Body"] = template.HTML(renderMarkdown(body))
+	}
+	s.render(w, "report", data)
+}
+
+// handleGenerate 生成或重新生成一份报告
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, s.base.Locale.Sprintf("拒绝来自其他站点的请求"), http.StatusForbidden)
+		return
+	}
+	reportType := r.FormValue("t")
+	key := r.FormValue("p")
+	config, ok := s.config(reportType, key)
+	if !ok {
+		http.Error(w, s.base.Locale.Sprintf("不支持的报告类型：%s", reportType), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	generator, err := NewGenerator(config)
+	if err == nil {
+		err = generator.Generate(config.SourceDir, nil)
+	}
+	s.mu.Unlock()
+
+	target := "/report?t=" + url.QueryEscape(reportType) + "&p=" + url.QueryEscape(key)
+	if err != nil {
+		target += "&error=" + url.QueryEscape(err.Error())
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// render 渲染页面模板
+func (s *Server) render(w http.ResponseWriter, name string, data map[string]interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.tmpl.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var (
+	headingRegex     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	orderedItemRegex = regexp.MustCompile(`^\d+\.\s+`)
+	wikiLinkRegex    = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+	tagRegex         = regexp.MustCompile(`(^|\s)(#[^\s#]+)`)
+)
+
+// renderInline 转义一行文本，并标出 [[链接]] 和 #标签
+func renderInline(text string) string {
+	text = html.EscapeString(text)
+	text = wikiLinkRegex.ReplaceAllString(text, `<span class="link">$1</span>`)
+	return tagRegex.ReplaceAllString(text, `$1<span class="tag">$2</span>`)
+}
+
+// renderMarkdown 将报告正文转换为 HTML，只处理报告中用到的标题、列表和段落
+func renderMarkdown(content string) string {
+	var sb strings.Builder
+	list := ""
+	closeList := func() {
+		if list != "" {
+			sb.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			sb.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			closeList()
+		case headingRegex.MatchString(trimmed):
+			closeList()
+			match := headingRegex.FindStringSubmatch(trimmed)
+			sb.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", len(match[1]), renderInline(match[2]), len(match[1])))
+		case orderedItemRegex.MatchString(trimmed):
+			openList("ol")
+			sb.WriteString("<li>" + renderInline(orderedItemRegex.ReplaceAllString(trimmed, "")) + "</li>\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			openList("ul")
+			sb.WriteString("<li>" + renderInline(trimmed[2:]) + "</li>\n")
+		default:
+			closeList()
+			sb.WriteString("<p>" + renderInline(trimmed) + "</p>\n")
+		}
+	}
+	closeList()
+	return sb.String()
+}
+
+// serverTemplate 网页模板，不依赖任何外部资源
+const serverTemplate = `
+{{define "head"}}<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>reportgen</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", "Noto Sans CJK SC", sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; line-height: 1.6; }
+a { color: #0b5cad; text-decoration: none; }
+a:hover { text-decoration: underline; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #ddd; padding-bottom: .3em; }
+table { border-collapse: collapse; margin: 1em 0; }
+td, th { border: 1px solid #ccc; padding: .3em .8em; text-align: left; }
+ul.periods { list-style: none; padding: 0; }
+ul.periods li { display: flex; justify-content: space-between; align-items: center; padding: .3em 0; border-bottom: 1px solid #f0f0f0; }
+.done { color: #2a7a2a; }
+.error { color: #b00020; }
+.link { color: #6b3fa0; }
+.tag { background: #eef3fb; border-radius: 3px; padding: 0 .3em; color: #0b5cad; }
+.preview { border: 1px solid #e5e5e5; border-radius: 6px; padding: 0 1.5em; }
+button { cursor: pointer; padding: .2em 1em; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "foot"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "head"}}
+<h1>reportgen</h1>
+{{range .Levels}}
+<h2>{{T .Title}}</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if .Periods}}<ul class="periods">
+{{$kind := .Kind}}{{range .Periods}}<li>
+<a href="/report?t={{$kind}}&p={{query .Key}}" {{if .Generated}}class="done"{{end}}>{{describe .}}</a>
+<form method="post" action="/generate"><input type="hidden" name="t" value="{{$kind}}"><input type="hidden" name="p" value="{{.Key}}"><button>{{if .Generated}}{{T "重新生成"}}{{else}}{{T "生成"}}{{end}}</button></form>
+</li>
+{{end}}</ul>
+{{else}}<p>{{T "未找到可用的时间段"}}</p>{{end}}
+{{end}}
+{{template "foot"}}{{end}}
+
+{{define "report"}}{{template "head"}}
+<p><a href="/">← {{T "返回列表"}}</a></p>
+<h1>{{describe .Period}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="/generate"><input type="hidden" name="t" value="{{.Kind}}"><input type="hidden" name="p" value="{{.Period.Key}}"><button>{{if .Period.Generated}}{{T "重新生成"}}{{else}}{{T "生成"}}{{end}}</button></form>
+{{if .Period.Generated}}
+{{if .Fields}}<table>
+{{range .Fields}}<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>{{end}}
+<div class="preview">
+{{.Body}}
+</div>
+{{else}}<p>{{T "报告尚未生成"}}</p>{{end}}
+{{template "foot"}}{{end}}
+`
@@ -0,0 +1,55 @@
+package reportgen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerRejectsCrossSiteRequests(t *testing.T) {
+	s := NewServer(Config{WorkDir: t.TempDir()}, "127.0.0.1:8080")
+
+	tests := []struct {
+		name    string
+		host    string
+		headers map[string]string
+		want    int
+	}{
+		{"同源表单", "127.0.0.1:8080", map[string]string{"Origin": "http://127.0.0.1:8080"}, http.StatusBadRequest},
+		{"同源 Referer", "localhost:8080", map[string]string{"Referer": "http://localhost:8080/report"}, http.StatusBadRequest},
+		{"非浏览器客户端", "127.0.0.1:8080", nil, http.StatusBadRequest},
+		{"跨站表单", "127.0.0.1:8080", map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"跨站 Referer", "127.0.0.1:8080", map[string]string{"Referer": "https://evil.example/page"}, http.StatusForbidden},
+		{"DNS 重绑定", "evil.example:8080", map[string]string{"Origin": "http://evil.example:8080"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 报告类型无效，通过检查的请求返回 400
+			req := httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader("t=x&p=1"))
+			req.Host = tt.host
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("POST /generate 状态码 = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerPageLanguage(t *testing.T) {
+	for locale, want := range map[Locale]string{"": `<html lang="zh-CN">`, LocaleEn: `<html lang="en">`} {
+		s := NewServer(Config{WorkDir: t.TempDir(), Locale: locale}, "127.0.0.1:8080")
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = "127.0.0.1:8080"
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("界面语言为 %q 时页面应包含 %s", locale, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package reportgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SettingsFile 工作目录下的配置文件
+const SettingsFile = ".reportgen/config.json"
+
+// Terms 定义了笔记库中使用的目录名、部分标题和文档属性键名
+type Terms struct {
+	DailyDir     string `json:"daily_dir"`
+	WeeklyDir    string `json:"weekly_dir"`
+	MonthlyDir   string `json:"monthly_dir"`
+	SemesterDir  string `json:"semester_dir"`
+	YearlyDir    string `json:"yearly_dir"`
+	CustomDir    string `json:"custom_dir"`
+	AnalyticsDir string `json:"analytics_dir"`
+
+	SemesterSuffix  string `json:"semester_suffix"`  // 学期报文件名中学期之后的部分
+	YearlySuffix    string `json:"yearly_suffix"`    // 年报文件名中学年之后的部分
+	AnalyticsSuffix string `json:"analytics_suffix"` // 教学统计文件名中学期之后的部分
+
+	Teaching      string `json:"teaching"`
+	Listening     string `json:"listening"`
+	Training      string `json:"training"`
+	Miscellaneous string `json:"miscellaneous"`
+	Summary       string `json:"summary"`
+
+	WeekKey           string `json:"week_key"`
+	ListeningCountKey string `json:"listening_count_key"`
+	DormCountKey      string `json:"dorm_count_key"`
+	ExamCountKey      string `json:"exam_count_key"`
+	RangeNameKey      string `json:"range_name_key"`
+	StartDateKey      string `json:"start_date_key"`
+	EndDateKey        string `json:"end_date_key"`
+	CourseCountKey    string `json:"course_count_key"`
+	TeachingCountKey  string `json:"teaching_count_key"`
+
+	DormKeyword         string `json:"dorm_keyword"`
+	ExamKeyword         string `json:"exam_keyword"`
+	InvigilationKeyword string `json:"invigilation_keyword"`
+	DutyKeyword         string `json:"duty_keyword"`
+}
+
+// termPresets 内置的词汇预设
+var termPresets = map[string]Terms{
+	string(LocaleZhCN): {
+		DailyDir:     "日报",
+		WeeklyDir:    "周报",
+		MonthlyDir:   "月报",
+		SemesterDir:  "学期报",
+		YearlyDir:    "年报",
+		CustomDir:    "专题报",
+		AnalyticsDir: "统计报",
+
+		SemesterSuffix:  "流水账",
+		YearlySuffix:    " 学年",
+		AnalyticsSuffix: "教学统计",
+
+		Teaching:      TeachingSection,
+		Listening:     ListeningSection,
+		Training:      TrainingSection,
+		Miscellaneous: MiscellaneousSection,
+		Summary:       SummarySection,
+
+		WeekKey:           "周",
+		ListeningCountKey: "听课次数",
+		DormCountKey:      "查宿次数",
+		ExamCountKey:      "特种工监考",
+		RangeNameKey:      "名称",
+		StartDateKey:      "起始日期",
+		EndDateKey:        "结束日期",
+		CourseCountKey:    "课程数",
+		TeachingCountKey:  "教学次数",
+
+		DormKeyword:         "查宿",
+		ExamKeyword:         "特种工监考",
+		InvigilationKeyword: "监考",
+		DutyKeyword:         "值班",
+	},
+	string(LocaleEn): {
+		DailyDir:     "Daily",
+		WeeklyDir:    "Weekly",
+		MonthlyDir:   "Monthly",
+		SemesterDir:  "Semester",
+		YearlyDir:    "Yearly",
+		CustomDir:    "Custom",
+		AnalyticsDir: "Analytics",
+
+		SemesterSuffix:  " Log",
+		YearlySuffix:    " Academic Year",
+		AnalyticsSuffix: " Teaching Analytics",
+
+		Teaching:      "Teaching",
+		Listening:     "Observation",
+		Training:      "Training",
+		Miscellaneous: "Duties",
+		Summary:       "Summary",
+
+		WeekKey:           "week",
+		ListeningCountKey: "observations",
+		DormCountKey:      "dorm_checks",
+		ExamCountKey:      "exam_invigilations",
+		RangeNameKey:      "name",
+		StartDateKey:      "start",
+		EndDateKey:        "end",
+		CourseCountKey:    "courses",
+		TeachingCountKey:  "teaching_sessions",
+
+		DormKeyword:         "dorm check",
+		ExamKeyword:         "exam invigilation",
+		InvigilationKeyword: "invigilation",
+		DutyKeyword:         "on duty",
+	},
+}
+
+// DefaultTerms 返回默认的简体中文词汇
+func DefaultTerms() *Terms {
+	terms := termPresets[string(LocaleZhCN)]
+	return &terms
+}
+
+// LevelDirs 返回指定报告类型的源目录和目标目录名称
+func (t *Terms) LevelDirs(reportType string) (string, string, bool) {
+	switch reportType {
+	case "w":
+		return t.DailyDir, t.WeeklyDir, true
+	case "m":
+		return t.WeeklyDir, t.MonthlyDir, true
+	case "s":
+		return t.MonthlyDir, t.SemesterDir, true
+	case "y":
+		return t.SemesterDir, t.YearlyDir, true
+	case "c":
+		return t.DailyDir, t.CustomDir, true
+	case "a":
+		return t.DailyDir, t.AnalyticsDir, true
+	}
+	return "", "", false
+}
+
+// SemesterFile 生成学期报文件名
+func (t *Terms) SemesterFile(semester string) string {
+	return semester + t.SemesterSuffix + ".md"
+}
+
+// YearlyFile 生成年报文件名，学年从 year 年秋季到次年春季，如 2024 - 2025 学年.md
+func (t *Terms) YearlyFile(year string) string {
+	start, _ := strconv.Atoi(year)
+	return fmt.Sprintf("%s - %d%s.md", year, start+1, t.YearlySuffix)
+}
+
+// AnalyticsFile 生成教学统计报告文件名，ext 为扩展名
+func (t *Terms) AnalyticsFile(semester, ext string) string {
+	return semester + t.AnalyticsSuffix + ext
+}
+
+// Sections 按输出顺序返回各个部分的标题
+func (t *Terms) Sections() []string {
+	return []string{t.Teaching, t.Listening, t.Training, t.Miscellaneous}
+}
+
+// Settings 定义了工作目录下配置文件的结构
+type Settings struct {
+	Locale string          `json:"locale"` // 界面语言，为空时读取环境变量
+	Preset string          `json:"preset"` // 词汇预设 (zh-CN, en)，默认 zh-CN
+	Terms  json.RawMessage `json:"terms"`  // 覆盖预设中的部分词汇
+	Ranges []NamedRange    `json:"ranges"` // 自定义时间段
+
+	BackupKeep int `json:"backup_keep"` // 保留的备份次数，默认 DefaultBackupKeep
+}
+
+// LoadSettings 读取工作目录下的配置文件，文件不存在时返回空配置
+func LoadSettings(workDir string) (*Settings, error) {
+	settings := &Settings{}
+	path := filepath.Join(workDir, SettingsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, DetectLocale().Errorf("读取配置文件失败：%v", err)
+	}
+	text, err := decodeText(data, DetectLocale())
+	if err != nil {
+		return nil, DetectLocale().Errorf("读取配置文件失败：%v", err)
+	}
+	if err := json.Unmarshal([]byte(text), settings); err != nil {
+		return nil, DetectLocale().Errorf("解析配置文件 %s 失败：%v", path, err)
+	}
+	return settings, nil
+}
+
+// ResolveTerms 根据预设和覆盖项得到最终使用的词汇
+func (s *Settings) ResolveTerms() (*Terms, error) {
+	preset := s.Preset
+	if preset == "" {
+		preset = string(LocaleZhCN)
+	}
+	terms, ok := termPresets[preset]
+	if !ok {
+		return nil, DetectLocale().Errorf("不支持的词汇预设：%s", preset)
+	}
+	if len(s.Terms) > 0 {
+		if err := json.Unmarshal(s.Terms, &terms); err != nil {
+			return nil, DetectLocale().Errorf("解析配置文件 %s 失败：%v", SettingsFile, err)
+		}
+	}
+	return &terms, nil
+}
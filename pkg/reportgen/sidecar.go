@@ -0,0 +1,123 @@
+package reportgen
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sidecar 与报告同名的 .json 数据文件，供上级报告和其他工具读取准确的数据
+type Sidecar struct {
+	Kind     string           `json:"kind"`   // 报告类型
+	Period   string           `json:"period"` // 与 Config.SelectedPeriod 相同
+	Start    string           `json:"start"`  // 起始日期，格式 YYYY-MM-DD
+	End      string           `json:"end"`    // 结束日期（含），格式同上
+	Sources  []string         `json:"sources"`
+	Counters *SidecarCounters `json:"counters,omitempty"` // 没有统计数据的报告为空
+	Sections []SidecarSection `json:"sections"`
+}
+
+// SidecarCounters 报告的统计数据
+type SidecarCounters struct {
+	Listening int `json:"listening"`
+	Dorm      int `json:"dorm"`
+	Exam      int `json:"exam"`
+}
+
+// SidecarSection 报告中的一个部分，Lines 不含空行
+type SidecarSection struct {
+	Title string   `json:"title"`
+	Lines []string `json:"lines"`
+}
+
+// sidecarPath 返回报告对应的数据文件路径
+func sidecarPath(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + ".json"
+}
+
+// newSidecar 创建数据文件，Sections 在写入报告时填充
+func newSidecar(kind, period string, start, end time.Time, sources []Report) *Sidecar {
+	sidecar := &Sidecar{
+		Kind:    kind,
+		Period:  period,
+		Start:   start.Format("2006-01-02"),
+		End:     end.Format("2006-01-02"),
+		Sources: make([]string, 0, len(sources)),
+	}
+	for _, report := range sources {
+		sidecar.Sources = append(sidecar.Sources, reportName(report))
+	}
+	return sidecar
+}
+
+// sidecarSections 按出现顺序提取报告中的各个部分
+func sidecarSections(content string) []SidecarSection {
+	sections := []SidecarSection{}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			sections = append(sections, SidecarSection{Title: strings.TrimSpace(strings.TrimPrefix(line, "## ")), Lines: []string{}})
+			continue
+		}
+		if len(sections) > 0 && strings.TrimSpace(line) != "" {
+			last := &sections[len(sections)-1]
+			last.Lines = append(last.Lines, line)
+		}
+	}
+	return sections
+}
+
+// sectionMap 将各部分转换为 extractSections 的返回格式
+func (s *Sidecar) sectionMap() map[string][]string {
+	sections := make(map[string][]string)
+	for _, section := range s.Sections {
+		if len(section.Lines) > 0 {
+			sections[section.Title] = append(sections[section.Title], strings.Join(section.Lines, "\n"))
+		}
+	}
+	return sections
+}
+
+// writeReport 写入报告，Config.Sidecar 为真时同时写入数据文件
+func (g *BaseGenerator) writeReport(outputFile, content string, sidecar *Sidecar) error {
+	if err := g.Config.Backup.WriteFile(outputFile, []byte(content)); err != nil {
+		return err
+	}
+	if !g.Config.Sidecar || sidecar == nil {
+		return nil
+	}
+
+	sidecar.Sections = sidecarSections(content)
+	data, err := json.MarshalIndent(sidecar, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.Config.Backup.WriteFile(sidecarPath(outputFile), append(data, '\n'))
+}
+
+// readSidecar 读取报告对应的数据文件，数据文件不存在或早于报告时返回 nil
+func (g *BaseGenerator) readSidecar(report Report) *Sidecar {
+	fsys, name := g.sourceFS(sidecarPath(report.FilePath))
+	info, err := fs.Stat(fsys, name)
+	if err != nil || info.ModTime().Before(report.ModTime) {
+		return nil
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil
+	}
+	sidecar := &Sidecar{}
+	if err := json.Unmarshal(data, sidecar); err != nil {
+		return nil
+	}
+	return sidecar
+}
+
+// reportStats 返回下级报告中的查宿和特种工监考次数，优先读取数据文件
+func (g *BaseGenerator) reportStats(report Report, terms *Terms) (int, int) {
+	if sidecar := g.readSidecar(report); sidecar != nil && sidecar.Counters != nil {
+		return sidecar.Counters.Dorm, sidecar.Counters.Exam
+	}
+	return extractFrontMatterStats(report.Content, terms)
+}
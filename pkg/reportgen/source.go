@@ -0,0 +1,320 @@
+package reportgen
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source 读取笔记的文件系统，使用完毕后需调用 Close 关闭打开的归档
+type Source struct {
+	fs.FS
+	closer io.Closer // zip 归档，目录和 git 版本为空
+}
+
+// Close 关闭打开的归档
+func (s *Source) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// OpenSource 打开读取笔记的文件系统，spec 可以是：
+//
+//	目录路径              读取该目录
+//	归档.zip              读取 zip 归档，归档中只有一个顶层目录时读取该目录
+//	git:版本              读取工作目录所在 git 仓库中指定版本的文件，如 git:HEAD~3、git:v2024-autumn
+//
+// workDir 为工作目录，git 版本中的路径相对于工作目录
+func OpenSource(spec, workDir string, terms *Terms, locale Locale) (*Source, error) {
+	if terms == nil {
+		terms = DefaultTerms()
+	}
+	switch {
+	case strings.HasPrefix(spec, "git:"):
+		fsys, err := openGitTree(workDir, strings.TrimPrefix(spec, "git:"), locale)
+		if err != nil {
+			return nil, locale.Errorf("读取 git 版本 %s 失败：%v", strings.TrimPrefix(spec, "git:"), err)
+		}
+		// git 不保存空目录，各级报告目录即使为空也视为存在
+		for _, dir := range []string{terms.DailyDir, terms.WeeklyDir, terms.MonthlyDir, terms.SemesterDir, terms.YearlyDir} {
+			fsys.addDir(dir)
+		}
+		return &Source{FS: fsys}, nil
+
+	case strings.EqualFold(filepath.Ext(spec), ".zip"):
+		archive, err := zip.OpenReader(spec)
+		if err != nil {
+			return nil, locale.Errorf("打开归档 %s 失败：%v", spec, err)
+		}
+		return &Source{FS: archiveRoot(archive, terms), closer: archive}, nil
+
+	default:
+		info, err := os.Stat(spec)
+		if err != nil || !info.IsDir() {
+			return nil, locale.Errorf("不支持的笔记来源：%s", spec)
+		}
+		return &Source{FS: os.DirFS(spec)}, nil
+	}
+}
+
+// archiveRoot 归档根目录下没有日报目录且只有一个顶层目录时，返回该顶层目录
+func archiveRoot(fsys fs.FS, terms *Terms) fs.FS {
+	if _, err := fs.Stat(fsys, terms.DailyDir); err == nil {
+		return fsys
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil {
+		return fsys
+	}
+	return sub
+}
+
+// ValidateSource 验证笔记来源是否包含所需的子目录，terms 为空时使用默认词汇
+func ValidateSource(fsys fs.FS, terms *Terms, locale Locale) error {
+	if terms == nil {
+		terms = DefaultTerms()
+	}
+	for _, dir := range []string{terms.DailyDir, terms.WeeklyDir, terms.MonthlyDir, terms.SemesterDir, terms.YearlyDir} {
+		if _, err := fs.Stat(fsys, dir); err != nil {
+			return locale.Errorf("当前目录不完整，无法归纳总结：%s 目录不存在", dir)
+		}
+	}
+	return nil
+}
+
+// sourceFS 返回读取指定路径所用的文件系统及路径在其中的位置。
+// Config.Source 不为空且路径位于工作目录之下时从 Config.Source 读取，否则从磁盘读取
+func (g *BaseGenerator) sourceFS(name string) (fs.FS, string) {
+	if g.Config.Source != nil {
+		if rel, err := filepath.Rel(g.Config.WorkDir, name); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return g.Config.Source, filepath.ToSlash(rel)
+		}
+	}
+	return os.DirFS(filepath.Dir(name)), filepath.Base(name)
+}
+
+// gitFile git 版本中的一个文件
+type gitFile struct {
+	data []byte
+}
+
+// gitTree 某个 git 版本中工作目录下的笔记，只读取 Markdown 和 JSON 文件
+type gitTree struct {
+	files   map[string]*gitFile // 以 / 分隔的相对路径
+	dirs    map[string][]string // 目录下的文件和子目录名
+	modTime time.Time           // 该版本的提交时间
+}
+
+// git 在工作目录下执行 git 命令
+func git(workDir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", workDir}, args...)...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// openGitTree 从本地对象库读取指定版本中工作目录下的文件
+func openGitTree(workDir, rev string, locale Locale) (*gitTree, error) {
+	prefix, err := git(workDir, nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	commitTime, err := git(workDir, nil, "log", "-1", "--format=%ct", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(commitTime)), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	// 列出工作目录对应的树中的所有文件
+	treeish := rev + ":" + strings.TrimSpace(string(prefix))
+	listing, err := git(workDir, nil, "ls-tree", "-r", "-z", "--full-tree", treeish)
+	if err != nil {
+		return nil, err
+	}
+	var names, objects []string
+	for _, line := range strings.Split(string(listing), "\x00") {
+		// 格式：<mode> SP <type> SP <object> TAB <path>
+		meta, name, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		if ext := strings.ToLower(path.Ext(name)); ext != ".md" && ext != ".json" {
+			continue
+		}
+		names = append(names, name)
+		objects = append(objects, fields[2])
+	}
+
+	tree := &gitTree{
+		files:   make(map[string]*gitFile),
+		dirs:    map[string][]string{".": nil},
+		modTime: time.Unix(seconds, 0),
+	}
+	if len(objects) == 0 {
+		return tree, nil
+	}
+
+	// 一次性读取所有文件内容，输出格式：<object> SP blob SP <size> LF <contents> LF
+	out, err := git(workDir, strings.NewReader(strings.Join(objects, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(bytes.NewReader(out))
+	for _, name := range names {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, locale.Errorf("无法解析 git cat-file 输出：%s", strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		tree.add(name, data[:size])
+	}
+	return tree, nil
+}
+
+// add 添加文件，并在新出现的各级目录中登记
+func (t *gitTree) add(name string, data []byte) {
+	t.files[name] = &gitFile{data: data}
+	for child := name; child != "."; child = path.Dir(child) {
+		parent := path.Dir(child)
+		_, known := t.dirs[parent]
+		t.dirs[parent] = append(t.dirs[parent], path.Base(child))
+		if known {
+			break
+		}
+	}
+}
+
+// addDir 添加目录，目录已存在时不做任何操作
+func (t *gitTree) addDir(name string) {
+	if _, ok := t.dirs[name]; ok {
+		return
+	}
+	t.dirs[name] = nil
+	for child := name; child != "."; child = path.Dir(child) {
+		parent := path.Dir(child)
+		_, known := t.dirs[parent]
+		t.dirs[parent] = append(t.dirs[parent], path.Base(child))
+		if known {
+			break
+		}
+	}
+}
+
+// Open 实现 fs.FS 接口
+func (t *gitTree) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if file, ok := t.files[name]; ok {
+		return &gitOpenFile{info: gitFileInfo{path.Base(name), int64(len(file.data)), 0644, t.modTime}, reader: bytes.NewReader(file.data)}, nil
+	}
+	if children, ok := t.dirs[name]; ok {
+		entries := make([]fs.DirEntry, 0, len(children))
+		for _, child := range children {
+			full := path.Join(name, child)
+			info := gitFileInfo{child, 0, fs.ModeDir | 0755, t.modTime}
+			if file, ok := t.files[full]; ok {
+				info = gitFileInfo{child, int64(len(file.data)), 0644, t.modTime}
+			}
+			entries = append(entries, fs.FileInfoToDirEntry(info))
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+		return &gitOpenDir{info: gitFileInfo{path.Base(name), 0, fs.ModeDir | 0755, t.modTime}, entries: entries}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// gitFileInfo 实现 fs.FileInfo 接口
+type gitFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i gitFileInfo) Name() string       { return i.name }
+func (i gitFileInfo) Size() int64        { return i.size }
+func (i gitFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i gitFileInfo) ModTime() time.Time { return i.modTime }
+func (i gitFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i gitFileInfo) Sys() interface{}   { return nil }
+
+// gitOpenFile 打开的文件
+type gitOpenFile struct {
+	info   gitFileInfo
+	reader *bytes.Reader
+}
+
+func (f *gitOpenFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *gitOpenFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *gitOpenFile) Close() error               { return nil }
+
+// gitOpenDir 打开的目录
+type gitOpenDir struct {
+	info    gitFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *gitOpenDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *gitOpenDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+func (d *gitOpenDir) Close() error { return nil }
+
+// ReadDir 实现 fs.ReadDirFile 接口
+func (d *gitOpenDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
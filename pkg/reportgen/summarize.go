@@ -0,0 +1,240 @@
+package reportgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultLLMTimeout 请求大模型接口的默认超时时间
+const DefaultLLMTimeout = 60 * time.Second
+
+// LLMConfig 定义了调用本地大模型接口的配置
+type LLMConfig struct {
+	Endpoint string        // 接口地址，如 http://localhost:11434/api/chat
+	Model    string        // 模型名称
+	Timeout  time.Duration // 单次请求超时时间
+	Client   *http.Client  // 自定义 HTTP 客户端，为空时使用默认客户端
+}
+
+// defaultPrompts 各语言、各级报告默认的提示词模板
+var defaultPrompts = map[Locale]map[string]string{
+	LocaleZhCN: {
+		"w": "下面是一位教师本周工作记录中“{{.Section}}”部分的内容，请用简体中文写一段不超过 150 字的周总结，不要使用列表：\n\n{{.Content}}",
+		"m": "下面是一位教师 {{.Period}} 月工作记录中“{{.Section}}”部分的汇总，请用简体中文写一段不超过 200 字的月总结，突出重点工作：\n\n{{.Content}}",
+		"s": "下面是一位教师 {{.Period}} 学期工作记录中“{{.Section}}”部分的汇总，请用简体中文写一段不超过 300 字的学期总结，概括主要成绩与不足：\n\n{{.Content}}",
+		"y": "下面是一位教师 {{.Period}} 年度工作记录中“{{.Section}}”部分的汇总，请用简体中文写一段不超过 300 字的年度总结：\n\n{{.Content}}",
+		"c": "下面是一位教师在“{{.Period}}”期间工作记录中“{{.Section}}”部分的汇总，请用简体中文写一段不超过 200 字的专题总结：\n\n{{.Content}}",
+	},
+	LocaleEn: {
+		"w": "Below is the \"{{.Section}}\" section of a teacher's notes for this week. Write a single paragraph of at most 100 words summarising the week, without lists:\n\n{{.Content}}",
+		"m": "Below is the merged \"{{.Section}}\" section of a teacher's notes for month {{.Period}}. Write a single paragraph of at most 150 words highlighting the key work:\n\n{{.Content}}",
+		"s": "Below is the merged \"{{.Section}}\" section of a teacher's notes for the {{.Period}} semester. Write a summary of at most 200 words covering achievements and shortcomings:\n\n{{.Content}}",
+		"y": "Below is the merged \"{{.Section}}\" section of a teacher's notes for {{.Period}}. Write a yearly summary of at most 200 words:\n\n{{.Content}}",
+		"c": "Below is the merged \"{{.Section}}\" section of a teacher's notes during \"{{.Period}}\". Write a summary of at most 150 words for this period:\n\n{{.Content}}",
+	},
+}
+
+// promptData 提示词模板可用的字段
+type promptData struct {
+	Level   string
+	Period  string
+	Section string
+	Content string
+}
+
+// chatMessage 对话消息
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest 兼容 Ollama /api/chat 与 OpenAI /v1/chat/completions 的请求体
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// chatResponse 同时兼容 Ollama 与 OpenAI 两种返回格式
+type chatResponse struct {
+	Message *chatMessage `json:"message"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error json.RawMessage `json:"error"`
+}
+
+// loadPrompt 读取指定级别的提示词模板，工作目录下 .reportgen/prompts/<级别>.tmpl 优先
+func (g *BaseGenerator) loadPrompt() (*template.Template, error) {
+	prompts, ok := defaultPrompts[g.Config.Locale]
+	if !ok {
+		prompts = defaultPrompts[LocaleZhCN]
+	}
+	text := prompts[g.Config.ReportType]
+	if g.Config.WorkDir != "" {
+		path := filepath.Join(g.Config.WorkDir, ".reportgen", "prompts", g.Config.ReportType+".tmpl")
+		if data, err := os.ReadFile(path); err == nil {
+			text = string(data)
+		} else if !os.IsNotExist(err) {
+			return nil, g.Config.Locale.Errorf("读取提示词模板失败：%v", err)
+		}
+	}
+	if text == "" {
+		text = prompts["w"]
+	}
+
+	tmpl, err := template.New(g.Config.ReportType).Parse(text)
+	if err != nil {
+		return nil, g.Config.Locale.Errorf("解析提示词模板失败：%v", err)
+	}
+	return tmpl, nil
+}
+
+// appendSummary 将各部分内容逐一发送给大模型，并在报告末尾追加总结部分
+func (g *BaseGenerator) appendSummary(content string) (string, error) {
+	if g.Config.LLM == nil || g.Config.LLM.Endpoint == "" {
+		return content, nil
+	}
+
+	tmpl, err := g.loadPrompt()
+	if err != nil {
+		return "", err
+	}
+
+	terms := g.terms()
+	sections := g.extractSections(content)
+	var summary strings.Builder
+	for _, section := range terms.Sections() {
+		text := strings.TrimSpace(strings.Join(sections[section], "\n"))
+		if text == "" || text == "无" {
+			continue
+		}
+
+		var prompt bytes.Buffer
+		if err := tmpl.Execute(&prompt, promptData{
+			Level:   g.Config.ReportType,
+			Period:  g.Config.SelectedPeriod,
+			Section: section,
+			Content: text,
+		}); err != nil {
+			return "", g.Config.Locale.Errorf("生成提示词失败：%v", err)
+		}
+
+		reply, err := g.summarize(prompt.String())
+		if err != nil {
+			return "", g.Config.Locale.Errorf("生成“%s”部分总结失败：%v", section, err)
+		}
+		if reply == "" {
+			continue
+		}
+
+		summary.WriteString(fmt.Sprintf("\n\n### %s\n\n%s", section, reply))
+	}
+
+	if summary.Len() == 0 {
+		return content, nil
+	}
+	return strings.TrimRight(content, "\n") + fmt.Sprintf("\n\n## %s", terms.Summary) + summary.String() + "\n", nil
+}
+
+// summarize 返回提示词对应的回复，命中缓存时不再请求接口
+func (g *BaseGenerator) summarize(prompt string) (string, error) {
+	llm := g.Config.LLM
+
+	// 不同接口的同名模型回复不同，缓存按接口地址、模型和提示词区分
+	hash := sha256.Sum256([]byte(llm.Endpoint + "\x00" + llm.Model + "\x00" + prompt))
+	key := hex.EncodeToString(hash[:])
+
+	var cachePath string
+	if g.Config.WorkDir != "" {
+		cachePath = filepath.Join(g.Config.WorkDir, ".reportgen", "cache", key+".md")
+		if data, err := os.ReadFile(cachePath); err == nil {
+			return string(data), nil
+		}
+	}
+
+	reply, err := requestChat(llm, g.Config.Locale, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+			return "", g.Config.Locale.Errorf("创建缓存目录失败：%v", err)
+		}
+		if err := os.WriteFile(cachePath, []byte(reply), 0644); err != nil {
+			return "", g.Config.Locale.Errorf("写入缓存失败：%v", err)
+		}
+	}
+	return reply, nil
+}
+
+// requestChat 向 OpenAI 兼容或 Ollama 的对话接口发送一次非流式请求
+func requestChat(llm *LLMConfig, locale Locale, prompt string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:    llm.Model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+		Stream:   false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	timeout := llm.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLLMTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, llm.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := llm.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", locale.Errorf("接口返回 %s：%s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", locale.Errorf("解析接口返回内容失败：%v", err)
+	}
+	if len(result.Error) > 0 && string(result.Error) != "null" {
+		return "", locale.Errorf("接口返回错误：%s", string(result.Error))
+	}
+
+	switch {
+	case result.Message != nil:
+		return strings.TrimSpace(result.Message.Content), nil
+	case len(result.Choices) > 0:
+		return strings.TrimSpace(result.Choices[0].Message.Content), nil
+	}
+	return "", locale.Errorf("接口返回内容为空")
+}
@@ -0,0 +1,191 @@
+package reportgen
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLLM 启动一个模拟大模型接口的测试服务器，记录收到的请求数和最后一次的提示词
+type fakeLLM struct {
+	server *httptest.Server
+	calls  atomic.Int32
+	prompt atomic.Value
+}
+
+func newFakeLLM(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *fakeLLM {
+	t.Helper()
+	f := &fakeLLM{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls.Add(1)
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求体失败：%v", err)
+		}
+		if req.Stream {
+			t.Errorf("请求应为非流式")
+		}
+		if len(req.Messages) > 0 {
+			f.prompt.Store(req.Messages[0].Content)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// config 返回指向测试服务器的接口配置
+func (f *fakeLLM) config(timeout time.Duration) *LLMConfig {
+	return &LLMConfig{
+		Endpoint: f.server.URL + "/api/chat",
+		Model:    "test",
+		Timeout:  timeout,
+		Client:   f.server.Client(),
+	}
+}
+
+// reply 返回固定内容的处理函数
+func reply(body string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestRequestChatOllama(t *testing.T) {
+	llm := newFakeLLM(t, reply(`{"model":"test","message":{"role":"assistant","content":" 本周完成了教学任务。\n"},"done":true}`))
+
+	got, err := requestChat(llm.config(0), LocaleZhCN, "提示词")
+	if err != nil {
+		t.Fatalf("requestChat() error = %v", err)
+	}
+	if want := "本周完成了教学任务。"; got != want {
+		t.Errorf("requestChat() = %q, want %q", got, want)
+	}
+	if prompt := llm.prompt.Load(); prompt != "提示词" {
+		t.Errorf("接口收到的提示词 = %v, want %q", prompt, "提示词")
+	}
+}
+
+func TestRequestChatOpenAI(t *testing.T) {
+	llm := newFakeLLM(t, reply(`{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"本月重点是期中考试。"}}]}`))
+
+	got, err := requestChat(llm.config(0), LocaleZhCN, "提示词")
+	if err != nil {
+		t.Fatalf("requestChat() error = %v", err)
+	}
+	if want := "本月重点是期中考试。"; got != want {
+		t.Errorf("requestChat() = %q, want %q", got, want)
+	}
+}
+
+func TestRequestChatTimeout(t *testing.T) {
+	llm := newFakeLLM(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	start := time.Now()
+	if _, err := requestChat(llm.config(50*time.Millisecond), LocaleZhCN, "提示词"); err == nil {
+		t.Fatal("requestChat() 超时后应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("requestChat() 用时 %v，未按超时时间返回", elapsed)
+	}
+}
+
+func TestRequestChatStatus(t *testing.T) {
+	llm := newFakeLLM(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	})
+
+	_, err := requestChat(llm.config(0), LocaleZhCN, "提示词")
+	if err == nil {
+		t.Fatal("requestChat() 接口返回 404 时应返回错误")
+	}
+	if !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("requestChat() error = %q，应包含状态码和返回内容", err)
+	}
+}
+
+func TestAppendSummaryPromptOverride(t *testing.T) {
+	workDir := t.TempDir()
+	promptDir := filepath.Join(workDir, ".reportgen", "prompts")
+	if err := os.MkdirAll(promptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(promptDir, "w.tmpl"), []byte("自定义 {{.Level}} {{.Period}} {{.Section}}：{{.Content}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	llm := newFakeLLM(t, reply(`{"message":{"role":"assistant","content":"按时完成。"}}`))
+	g := &BaseGenerator{Config: &Config{
+		WorkDir:        workDir,
+		ReportType:     "w",
+		SelectedPeriod: "10.14",
+		LLM:            llm.config(0),
+	}}
+
+	got, err := g.appendSummary("## 教学\n\n- 上课\n")
+	if err != nil {
+		t.Fatalf("appendSummary() error = %v", err)
+	}
+	if want := "自定义 w 10.14 教学：- 上课"; llm.prompt.Load() != want {
+		t.Errorf("接口收到的提示词 = %v, want %q", llm.prompt.Load(), want)
+	}
+	if want := "## 教学\n\n- 上课\n\n## 总结\n\n### 教学\n\n按时完成。\n"; got != want {
+		t.Errorf("appendSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeCache(t *testing.T) {
+	workDir := t.TempDir()
+	llm := newFakeLLM(t, reply(`{"message":{"role":"assistant","content":"按时完成。"}}`))
+	g := &BaseGenerator{Config: &Config{WorkDir: workDir, ReportType: "w", LLM: llm.config(0)}}
+
+	for i := 0; i < 2; i++ {
+		got, err := g.summarize("提示词")
+		if err != nil {
+			t.Fatalf("summarize() error = %v", err)
+		}
+		if got != "按时完成。" {
+			t.Errorf("summarize() = %q, want %q", got, "按时完成。")
+		}
+	}
+	if calls := llm.calls.Load(); calls != 1 {
+		t.Errorf("接口被请求 %d 次，命中缓存后应只请求 1 次", calls)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(workDir, ".reportgen", "cache"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf(".reportgen/cache 中应有 1 个缓存文件，got %d (%v)", len(entries), err)
+	}
+}
+
+func TestSummarizeCacheByEndpoint(t *testing.T) {
+	workDir := t.TempDir()
+	ollama := newFakeLLM(t, reply(`{"message":{"role":"assistant","content":"Ollama 的回复"}}`))
+	openai := newFakeLLM(t, reply(`{"choices":[{"message":{"role":"assistant","content":"OpenAI 的回复"}}]}`))
+
+	for _, tt := range []struct {
+		llm  *fakeLLM
+		want string
+	}{{ollama, "Ollama 的回复"}, {openai, "OpenAI 的回复"}} {
+		g := &BaseGenerator{Config: &Config{WorkDir: workDir, ReportType: "w", LLM: tt.llm.config(0)}}
+		got, err := g.summarize("提示词")
+		if err != nil {
+			t.Fatalf("summarize() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("summarize() = %q, want %q，同名模型换用其他接口后不应命中缓存", got, tt.want)
+		}
+	}
+}
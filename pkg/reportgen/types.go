@@ -1,5 +1,10 @@
 package reportgen
 
+import (
+	"io/fs"
+	"time"
+)
+
 // Report 定义了报告的基本结构
 type Report struct {
 	Content   string
@@ -10,29 +15,39 @@ type Report struct {
 	Semester  string              // 学期
 	Year      string              // 年份
 	Sections  map[string][]string // 各个部分的内容
+	ModTime   time.Time           // 文件修改时间
 }
 
 // ReportGenerator 定义了报告生成器的接口
 type ReportGenerator interface {
 	// Generate 生成报告
 	Generate(sourcePath string, params map[string]string) error
-	// GetAvailablePeriods 获取可用的时间段
-	GetAvailablePeriods(sourcePath string) ([]string, error)
+	// GetAvailablePeriods 获取可用的时间段，按时间先后排序
+	GetAvailablePeriods(sourcePath string) ([]Period, error)
 }
 
 // Config 定义了配置结构
 type Config struct {
+	WorkDir        string
 	SourceDir      string
 	TargetDir      string
 	ReportType     string
 	SelectedPeriod string
 	Formatting     bool
+	Locale         Locale       // 界面语言，为空时使用简体中文
+	Terms          *Terms       // 笔记库词汇，为空时使用默认的简体中文词汇
+	Ranges         []NamedRange // 自定义时间段
+	LLM            *LLMConfig   // 为空时不生成总结部分
+	Sidecar        bool         // 是否同时输出 .json 数据文件
+	Backup         *Backup      // 覆盖报告前的备份，为空时不备份
+	Source         fs.FS        // 读取笔记的文件系统，根目录对应 WorkDir，为空时从磁盘读取
 }
 
-// Section 定义了报告中的各个部分
+// Section 定义了报告中各个部分的默认标题
 const (
 	TeachingSection      = "教学"
 	ListeningSection     = "听课"
 	TrainingSection      = "培训学习"
 	MiscellaneousSection = "杂事"
+	SummarySection       = "总结" // 由大模型生成的总结部分
 )
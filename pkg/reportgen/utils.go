@@ -9,15 +9,9 @@ import (
 	"time"
 )
 
-// ValidateWorkingDir 验证工作目录是否包含所需的子目录
-func ValidateWorkingDir(dirPath string) error {
-	requiredDirs := []string{"日报", "周报", "月报", "学期报", "年报"}
-	for _, dir := range requiredDirs {
-		if _, err := os.Stat(filepath.Join(dirPath, dir)); err != nil {
-			return fmt.Errorf("当前目录不完整，无法归纳总结：%s 目录不存在", dir)
-		}
-	}
-	return nil
+// ValidateWorkingDir 验证工作目录是否包含所需的子目录，terms 为空时使用默认词汇
+func ValidateWorkingDir(dirPath string, terms *Terms, locale Locale) error {
+	return ValidateSource(os.DirFS(dirPath), terms, locale)
 }
 
 // ExtractDateFromFilename 从文件名中提取日期
@@ -29,7 +23,7 @@ func ExtractDateFromFilename(filename string) (time.Time, error) {
 	dateRegex := regexp.MustCompile(`^(\d{8})`)
 	match := dateRegex.FindString(base)
 	if match == "" {
-		return time.Time{}, fmt.Errorf("无法从文件名 %s 中提取日期", filename)
+		return time.Time{}, DetectLocale().Errorf("无法从文件名 %s 中提取日期", filename)
 	}
 
 	return time.Parse("20060102", match)
@@ -44,7 +38,7 @@ func ExtractMonthFromFilename(filename string) (time.Time, error) {
 	monthRegex := regexp.MustCompile(`^(\d{6})$`)
 	match := monthRegex.FindString(base)
 	if match == "" {
-		return time.Time{}, fmt.Errorf("无法从月报文件名 %s 中提取日期", filename)
+		return time.Time{}, DetectLocale().Errorf("无法从月报文件名 %s 中提取日期", filename)
 	}
 
 	// 解析日期，将月份设置为该月的第一天
@@ -64,6 +58,85 @@ func GetSemesterPeriod(date time.Time) string {
 	return fmt.Sprintf("%d - %d 秋", year, year+1)
 }
 
+// SemesterRange 返回学期的起止日期，与 GetSemesterPeriod 的划分一致：
+// 秋季学期为 8 月至次年 1 月，春季学期为 2 月至 7 月
+func SemesterRange(semester string) (time.Time, time.Time, error) {
+	var startYear, endYear int
+	var term string
+	if _, err := fmt.Sscanf(semester, "%d - %d %s", &startYear, &endYear, &term); err != nil {
+		return time.Time{}, time.Time{}, DetectLocale().Errorf("无法解析学期 %s", semester)
+	}
+
+	switch term {
+	case "秋":
+		start := time.Date(startYear, time.August, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 6, -1), nil
+	case "春":
+		start := time.Date(endYear, time.February, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 6, -1), nil
+	}
+	return time.Time{}, time.Time{}, DetectLocale().Errorf("无法解析学期 %s", semester)
+}
+
+// frontMatterValue 读取文档属性中指定键的值，并去除两侧的引号
+func frontMatterValue(content, key string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return ""
+	}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "---" {
+			break
+		}
+		if strings.HasPrefix(line, key+":") {
+			return strings.Trim(strings.TrimPrefix(line, key+":"), "\" ")
+		}
+	}
+	return ""
+}
+
+// FrontMatterField 文档属性中的一项
+type FrontMatterField struct {
+	Key   string
+	Value string
+}
+
+// SplitFrontMatter 将报告拆分为文档属性和正文，没有文档属性时原样返回正文
+func SplitFrontMatter(content string) ([]FrontMatterField, string) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, content
+	}
+
+	var fields []FrontMatterField
+	for i, line := range lines[1:] {
+		if strings.TrimSpace(line) == "---" {
+			return fields, strings.Join(lines[i+2:], "\n")
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields = append(fields, FrontMatterField{Key: strings.TrimSpace(key), Value: strings.Trim(strings.TrimSpace(value), "\"")})
+		}
+	}
+	return nil, content
+}
+
+// extractLinks 提取报告正文第一个部分之前的 [[链接]]，即生成报告时写入的源文件列表
+func extractLinks(content string) []string {
+	var links []string
+	linkRegex := regexp.MustCompile(`^\[\[([^\]]+)\]\]$`)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "## ") {
+			break
+		}
+		if match := linkRegex.FindStringSubmatch(line); match != nil {
+			links = append(links, match[1])
+		}
+	}
+	return links
+}
+
 // ProcessEmptyContent 处理内容中的"无"，确保每个部分只保留一个"无"
 func ProcessEmptyContent(content string) string {
 	if strings.TrimSpace(content) == "" {
@@ -0,0 +1,301 @@
+package reportgen
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 一致性检查发现的问题类型
+const (
+	IssueOrphan     = "orphan"      // 日报未被任何周报覆盖
+	IssueCrossMonth = "cross-month" // 周报跨月，部分日报被计入错误的月份
+	IssueDuplicate  = "duplicate"   // 同一份报告被多份上级报告重复计入
+	IssueStale      = "stale"       // 上级报告缺少源文件或早于源文件
+	IssueCounter    = "counter"     // 上级报告的统计数据与下级报告之和不一致
+	IssueEncoding   = "encoding"    // 文件编码无法识别，已跳过
+)
+
+// Issue 描述一致性检查发现的一个问题
+type Issue struct {
+	Kind    string
+	File    string
+	Message string
+}
+
+// hierarchyLevel 描述一层“源报告 → 目标报告”的归纳关系
+type hierarchyLevel struct {
+	sources  []Report
+	targets  []Report
+	sourceOf func(report Report) string // 源报告所属的时间段，为空表示无法归属
+	targetOf func(report Report) string // 目标报告对应的时间段
+	linked   bool                       // 目标报告是否以 [[链接]] 列出源报告
+	counters bool                       // 是否核对统计数据
+}
+
+// weeklyRangeRegex 匹配周报文件名中的首尾日期
+var weeklyRangeRegex = regexp.MustCompile(`^(\d{8}) - (\d{8})`)
+
+// weeklyRange 从周报文件名中解析首尾日期
+func weeklyRange(path string) (time.Time, time.Time, bool) {
+	match := weeklyRangeRegex.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err1 := time.Parse("20060102", match[1])
+	end, err2 := time.Parse("20060102", match[2])
+	return start, end, err1 == nil && err2 == nil
+}
+
+// reportName 返回不含扩展名的报告文件名，与 [[链接]] 的写法一致
+func reportName(report Report) string {
+	return strings.TrimSuffix(filepath.Base(report.FilePath), ".md")
+}
+
+// Verify 重建日报、周报、月报、学期报和年报之间的层级关系并检查其一致性
+func Verify(workDir string, terms *Terms, locale Locale) ([]Issue, error) {
+	if terms == nil {
+		terms = DefaultTerms()
+	}
+	g := &BaseGenerator{Config: &Config{WorkDir: workDir, Terms: terms, Locale: locale}}
+
+	var issues []Issue
+	read := func(dir string) ([]Report, error) {
+		reports, undecodable, err := g.readNotes(filepath.Join(workDir, dir))
+		if err != nil {
+			return nil, locale.Errorf("读取 %s 目录失败：%v", dir, err)
+		}
+		issues = append(issues, undecodable...)
+		return reports, nil
+	}
+	daily, err := read(terms.DailyDir)
+	if err != nil {
+		return nil, err
+	}
+	weekly, err := read(terms.WeeklyDir)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := read(terms.MonthlyDir)
+	if err != nil {
+		return nil, err
+	}
+	semester, err := read(terms.SemesterDir)
+	if err != nil {
+		return nil, err
+	}
+	yearly, err := read(terms.YearlyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	issues = append(issues, checkDailyCoverage(daily, weekly, terms, locale)...)
+
+	levels := []hierarchyLevel{
+		{
+			sources:  daily,
+			targets:  weekly,
+			sourceOf: func(r Report) string { return extractWeekFromContent(r.Content, terms.WeekKey) },
+			targetOf: func(r Report) string { return frontMatterValue(r.Content, terms.WeekKey) },
+			linked:   true,
+		},
+		{
+			sources: weekly,
+			targets: monthly,
+			sourceOf: func(r Report) string {
+				date, err := ExtractDateFromFilename(r.FilePath)
+				if err != nil {
+					return ""
+				}
+				return date.Format("200601")
+			},
+			targetOf: reportName,
+			linked:   true,
+			counters: true,
+		},
+		{
+			sources: monthly,
+			targets: semester,
+			sourceOf: func(r Report) string {
+				date, err := ExtractMonthFromFilename(r.FilePath)
+				if err != nil {
+					return ""
+				}
+				return GetSemesterPeriod(date)
+			},
+			targetOf: func(r Report) string { return strings.TrimSuffix(reportName(r), terms.SemesterSuffix) },
+			linked:   true,
+			counters: true,
+		},
+		{
+			sources:  semester,
+			targets:  yearly,
+			sourceOf: yearPrefix,
+			targetOf: yearPrefix,
+		},
+	}
+	for _, level := range levels {
+		issues = append(issues, checkLevel(level, terms, locale)...)
+	}
+
+	return issues, nil
+}
+
+// yearPrefix 返回文件名开头的四位年份
+func yearPrefix(report Report) string {
+	base := filepath.Base(report.FilePath)
+	if len(base) >= 4 {
+		if _, err := strconv.Atoi(base[:4]); err == nil {
+			return base[:4]
+		}
+	}
+	return ""
+}
+
+// checkDailyCoverage 检查未被周报覆盖的日报，以及跨月周报造成的月份归属错误
+func checkDailyCoverage(daily, weekly []Report, terms *Terms, locale Locale) []Issue {
+	var issues []Issue
+
+	for _, report := range daily {
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			issues = append(issues, Issue{IssueOrphan, report.FilePath, locale.Sprintf("无法从文件名中提取日期")})
+			continue
+		}
+		if extractWeekFromContent(report.Content, terms.WeekKey) == "" {
+			issues = append(issues, Issue{IssueOrphan, report.FilePath, locale.Sprintf("缺少“%s”属性，无法归入任何周报", terms.WeekKey)})
+			continue
+		}
+
+		covered := false
+		for _, week := range weekly {
+			start, end, ok := weeklyRange(week.FilePath)
+			if ok && !date.Before(start) && !date.After(end) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			issues = append(issues, Issue{IssueOrphan, report.FilePath, locale.Sprintf("%s 的日报未被任何周报覆盖", date.Format("2006-01-02"))})
+		}
+	}
+
+	for _, week := range weekly {
+		start, end, ok := weeklyRange(week.FilePath)
+		if !ok || start.Format("200601") == end.Format("200601") {
+			continue
+		}
+		// 月报按周报的起始日期归属月份，其余月份的日报会被计入起始月份，没有这样的日报时不必提示
+		moved := 0
+		for _, report := range daily {
+			date, err := ExtractDateFromFilename(report.FilePath)
+			if err == nil && !date.Before(start) && !date.After(end) && date.Format("200601") != start.Format("200601") {
+				moved++
+			}
+		}
+		if moved == 0 {
+			continue
+		}
+		issues = append(issues, Issue{IssueCrossMonth, week.FilePath, locale.Sprintf("周报跨越 %s 与 %s，整周计入 %s 月报，其中 %d 篇日报属于 %s", start.Format("200601"), end.Format("200601"), start.Format("200601"), moved, end.Format("200601"))})
+	}
+
+	return issues
+}
+
+// checkLevel 检查一层归纳关系中缺失、重复、过期的源报告以及统计数据
+func checkLevel(level hierarchyLevel, terms *Terms, locale Locale) []Issue {
+	var issues []Issue
+
+	sourcesByPeriod := make(map[string][]Report)
+	sourcesByName := make(map[string]Report)
+	for _, source := range level.sources {
+		sourcesByName[reportName(source)] = source
+		if period := level.sourceOf(source); period != "" {
+			sourcesByPeriod[period] = append(sourcesByPeriod[period], source)
+		}
+	}
+
+	linkedBy := make(map[string][]string)
+	for _, target := range level.targets {
+		period := level.targetOf(target)
+		expected := sourcesByPeriod[period]
+
+		// 过期：源报告在目标报告生成之后又被修改
+		for _, source := range expected {
+			if source.ModTime.After(target.ModTime) {
+				issues = append(issues, Issue{IssueStale, target.FilePath, locale.Sprintf("%s 在报告生成后被修改，需要重新生成", reportName(source))})
+			}
+		}
+
+		if !level.linked {
+			continue
+		}
+
+		links := extractLinks(target.Content)
+		linkSet := make(map[string]bool)
+		for _, link := range links {
+			linkSet[link] = true
+			linkedBy[link] = append(linkedBy[link], reportName(target))
+			if _, ok := sourcesByName[link]; !ok {
+				issues = append(issues, Issue{IssueStale, target.FilePath, locale.Sprintf("引用的 %s 已不存在", link)})
+			}
+		}
+		for _, source := range expected {
+			if !linkSet[reportName(source)] {
+				issues = append(issues, Issue{IssueStale, target.FilePath, locale.Sprintf("缺少 %s，需要重新生成", reportName(source))})
+			}
+		}
+
+		if level.counters {
+			issues = append(issues, checkCounters(target, links, sourcesByName, terms, locale)...)
+		}
+	}
+
+	// 重复：同一份源报告被多份目标报告引用
+	var names []string
+	for name := range linkedBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if targets := linkedBy[name]; len(targets) > 1 {
+			file := name
+			if source, ok := sourcesByName[name]; ok {
+				file = source.FilePath
+			}
+			issues = append(issues, Issue{IssueDuplicate, file, locale.Sprintf("被 %s 重复计入", strings.Join(targets, "、"))})
+		}
+	}
+
+	return issues
+}
+
+// checkCounters 核对目标报告的统计数据是否等于其引用的源报告之和
+func checkCounters(target Report, links []string, sourcesByName map[string]Report, terms *Terms, locale Locale) []Issue {
+	var issues []Issue
+	for _, key := range []string{terms.ListeningCountKey, terms.DormCountKey, terms.ExamCountKey} {
+		value := frontMatterValue(target.Content, key)
+		if value == "" {
+			continue
+		}
+		actual, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		sum := 0
+		for _, link := range links {
+			if source, ok := sourcesByName[link]; ok {
+				n, _ := strconv.Atoi(frontMatterValue(source.Content, key))
+				sum += n
+			}
+		}
+		if actual != sum {
+			issues = append(issues, Issue{IssueCounter, target.FilePath, locale.Sprintf("%s 为 %d，但引用的报告合计为 %d", key, actual, sum)})
+		}
+	}
+	return issues
+}
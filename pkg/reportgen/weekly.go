@@ -1,20 +1,16 @@
 package reportgen
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
 // countListeningClasses 统计听课次数
-func countListeningClasses(content string) int {
+func countListeningClasses(content, section string) int {
 	count := 0
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "#### ") && strings.Contains(content, "## 听课") {
+	for _, line := range splitLines(content) {
+		if strings.HasPrefix(line, "#### ") && strings.Contains(content, "## "+section) {
 			count++
 		}
 	}
@@ -24,11 +20,9 @@ func countListeningClasses(content string) int {
 // countKeywordInSection 统计指定部分中关键词出现的次数
 func countKeywordInSection(content, section, keyword string) int {
 	count := 0
-	scanner := bufio.NewScanner(strings.NewReader(content))
 	inSection := false
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range splitLines(content) {
 		if strings.HasPrefix(line, "## ") {
 			inSection = strings.TrimPrefix(line, "## ") == section
 		} else if inSection && strings.Contains(line, keyword) {
@@ -41,11 +35,9 @@ func countKeywordInSection(content, section, keyword string) int {
 // removeKeywordLines 删除包含指定关键词的行
 func removeKeywordLines(content, section, keyword string) string {
 	var result strings.Builder
-	scanner := bufio.NewScanner(strings.NewReader(content))
 	inSection := false
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	for _, line := range splitLines(content) {
 		if strings.HasPrefix(line, "## ") {
 			inSection = strings.TrimPrefix(line, "## ") == section
 		}
@@ -57,24 +49,36 @@ func removeKeywordLines(content, section, keyword string) string {
 	return result.String()
 }
 
+// countDailyStats 统计由日报合并而来的内容中的听课、查宿和特种工监考次数，并删除统计过的行
+func countDailyStats(content string, terms *Terms) (string, int, int, int) {
+	listeningCount := countListeningClasses(content, terms.Listening)
+	dormCount := countKeywordInSection(content, terms.Miscellaneous, terms.DormKeyword)
+	examCount := countKeywordInSection(content, terms.Miscellaneous, terms.ExamKeyword)
+
+	content = removeKeywordLines(content, terms.Miscellaneous, terms.DormKeyword)
+	content = removeKeywordLines(content, terms.Miscellaneous, terms.ExamKeyword)
+	return content, listeningCount, dormCount, examCount
+}
+
 // Generate 生成周报
 func (g *WeeklyGenerator) Generate(sourcePath string, params map[string]string) error {
 	// 读取日报文件
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return fmt.Errorf("读取日报文件失败：%v", err)
+		return g.Config.Locale.Errorf("读取日报文件失败：%v", err)
 	}
+	terms := g.terms()
 
 	// 筛选指定周数的报告
 	var selectedReports []Report
 	for _, report := range reports {
-		if week := extractWeekFromContent(report.Content); week == g.Config.SelectedPeriod {
+		if week := extractWeekFromContent(report.Content, terms.WeekKey); week == g.Config.SelectedPeriod {
 			selectedReports = append(selectedReports, report)
 		}
 	}
 
 	if len(selectedReports) == 0 {
-		return fmt.Errorf("未找到第 %s 周的日报", g.Config.SelectedPeriod)
+		return g.Config.Locale.Errorf("未找到第 %s 周的日报", g.Config.SelectedPeriod)
 	}
 
 	// 生成日报链接列表
@@ -84,70 +88,84 @@ func (g *WeeklyGenerator) Generate(sourcePath string, params map[string]string)
 		dailyLinks.WriteString(fmt.Sprintf("[[%s]]\n\n", fileName))
 	}
 
-	// 合并报告内容并格式化
-	content := g.mergeSectionsAndFormat(selectedReports)
-
-	// 统计各项数据
-	listeningCount := countListeningClasses(content)
-	dormCount := countKeywordInSection(content, "杂事", "查宿")
-	examCount := countKeywordInSection(content, "杂事", "特种工监考")
-
-	// 删除统计过的行
-	content = removeKeywordLines(content, "杂事", "查宿")
-	content = removeKeywordLines(content, "杂事", "特种工监考")
+	// 合并报告内容并格式化，统计各项数据
+	content, listeningCount, dormCount, examCount := countDailyStats(g.mergeSectionsAndFormat(selectedReports), terms)
 
 	// 生成文档属性
 	var frontMatter strings.Builder
 	frontMatter.WriteString("---\n")
-	frontMatter.WriteString(fmt.Sprintf("周: \"%s\"\n", g.Config.SelectedPeriod))
-	frontMatter.WriteString(fmt.Sprintf("听课次数: \"%d\"\n", listeningCount))
-	frontMatter.WriteString(fmt.Sprintf("查宿次数: \"%d\"\n", dormCount))
-	frontMatter.WriteString(fmt.Sprintf("特种工监考: \"%d\"\n", examCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%s\"\n", terms.WeekKey, g.Config.SelectedPeriod))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ListeningCountKey, listeningCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.DormCountKey, dormCount))
+	frontMatter.WriteString(fmt.Sprintf("%s: \"%d\"\n", terms.ExamCountKey, examCount))
 	frontMatter.WriteString("---\n\n")
 
+	// 调用大模型生成总结
+	content, err = g.appendSummary(content)
+	if err != nil {
+		return err
+	}
+
 	// 在内容前添加日报链接和文档属性
 	content = frontMatter.String() + dailyLinks.String() + content
 
 	// 生成输出文件名
-	firstFile := filepath.Base(selectedReports[0].FilePath)
-	lastFile := filepath.Base(selectedReports[len(selectedReports)-1].FilePath)
-	firstDate := strings.TrimSuffix(firstFile, ".md")
-	lastDate := strings.TrimSuffix(lastFile, ".md")
-	outputFile := filepath.Join(g.Config.TargetDir, fmt.Sprintf("%s - %s.md", firstDate, lastDate))
+	outputFile := filepath.Join(g.Config.TargetDir, weeklyOutputName(selectedReports))
 
 	// 写入文件
-	return os.WriteFile(outputFile, []byte(content), 0644)
+	start, _ := ExtractDateFromFilename(selectedReports[0].FilePath)
+	end, _ := ExtractDateFromFilename(selectedReports[len(selectedReports)-1].FilePath)
+	sidecar := newSidecar("w", g.Config.SelectedPeriod, start, end, selectedReports)
+	sidecar.Counters = &SidecarCounters{Listening: listeningCount, Dorm: dormCount, Exam: examCount}
+	return g.writeReport(outputFile, content, sidecar)
+}
+
+// weeklyOutputName 根据首尾日报文件名生成周报文件名
+func weeklyOutputName(reports []Report) string {
+	firstDate := strings.TrimSuffix(filepath.Base(reports[0].FilePath), ".md")
+	lastDate := strings.TrimSuffix(filepath.Base(reports[len(reports)-1].FilePath), ".md")
+	return fmt.Sprintf("%s - %s.md", firstDate, lastDate)
 }
 
 // GetAvailablePeriods 获取可用的周数
-func (g *WeeklyGenerator) GetAvailablePeriods(sourcePath string) ([]string, error) {
+func (g *WeeklyGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取日报文件失败：%v", err)
+		return nil, g.Config.Locale.Errorf("读取日报文件失败：%v", err)
 	}
 
-	weekMap := make(map[string]bool)
+	weekMap := make(map[string]*Period)
+	weekReports := make(map[string][]Report)
 	for _, report := range reports {
-		if week := extractWeekFromContent(report.Content); week != "" {
-			weekMap[week] = true
+		week := extractWeekFromContent(report.Content, g.terms().WeekKey)
+		if week == "" {
+			continue
+		}
+		date, err := ExtractDateFromFilename(report.FilePath)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := weekMap[week]; !ok {
+			weekMap[week] = &Period{Kind: "w", Key: week, Label: g.Config.Locale.Sprintf("第 %s 周", week)}
 		}
+		extendPeriod(weekMap[week], date, date)
+		weekReports[week] = append(weekReports[week], report)
 	}
 
-	var weeks []string
-	for week := range weekMap {
-		weeks = append(weeks, week)
+	for week, period := range weekMap {
+		period.Target = weeklyOutputName(weekReports[week])
+		period.Generated = g.targetExists(period.Target)
 	}
-	return weeks, nil
+	return sortedPeriods(weekMap), nil
 }
 
-// extractWeekFromContent 从文件内容中提取周数
-func extractWeekFromContent(content string) string {
-	scanner := bufio.NewScanner(strings.NewReader(content))
+// extractWeekFromContent 从文件内容的文档属性中提取周数
+func extractWeekFromContent(content, key string) string {
 	inFrontMatter := false
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "---" {
+	for _, line := range splitLines(content) {
+		if strings.TrimSpace(line) == "---" {
 			if !inFrontMatter {
 				inFrontMatter = true
 				continue
@@ -155,8 +173,8 @@ func extractWeekFromContent(content string) string {
 			break
 		}
 
-		if inFrontMatter && strings.HasPrefix(line, "周:") {
-			return strings.Trim(strings.TrimPrefix(line, "周:"), "\" ")
+		if inFrontMatter && strings.HasPrefix(line, key+":") {
+			return strings.Trim(strings.TrimPrefix(line, key+":"), "\" ")
 		}
 	}
 
@@ -2,11 +2,10 @@ package reportgen
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
-	// "strings"
+	"strconv"
+	"time"
 )
 
 // Generate 生成年报
@@ -14,7 +13,7 @@ func (g *YearlyGenerator) Generate(sourcePath string, params map[string]string)
 	// 读取学期报文件
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return fmt.Errorf("读取学期报文件失败：%v", err)
+		return g.Config.Locale.Errorf("读取学期报文件失败：%v", err)
 	}
 
 	// 筛选指定年份的报告
@@ -34,43 +33,60 @@ func (g *YearlyGenerator) Generate(sourcePath string, params map[string]string)
 	}
 
 	if len(selectedReports) == 0 {
-		return fmt.Errorf("未找到 %s 年的学期报", g.Config.SelectedPeriod)
+		return g.Config.Locale.Errorf("未找到 %s 年的学期报", g.Config.SelectedPeriod)
 	}
 
 	// 合并报告内容并格式化
 	content := g.mergeSectionsAndFormat(selectedReports)
 
+	// 调用大模型生成总结
+	content, err = g.appendSummary(content)
+	if err != nil {
+		return err
+	}
+
 	// 生成输出文件名
-	outputFile := filepath.Join(g.Config.TargetDir, fmt.Sprintf("%s - %s 学年.md", g.Config.SelectedPeriod, string(g.Config.SelectedPeriod[0:4])))
+	outputFile := filepath.Join(g.Config.TargetDir, g.terms().YearlyFile(g.Config.SelectedPeriod))
 
-	// 写入文件
-	return os.WriteFile(outputFile, []byte(content), 0644)
+	// 写入文件，学年从当年秋季学期开始，到次年春季学期结束
+	year, _ := strconv.Atoi(g.Config.SelectedPeriod)
+	start := time.Date(year, time.August, 1, 0, 0, 0, 0, time.UTC)
+	return g.writeReport(outputFile, content, newSidecar("y", g.Config.SelectedPeriod, start, start.AddDate(1, 0, -1), selectedReports))
 }
 
 // GetAvailablePeriods 获取可用的年份
-func (g *YearlyGenerator) GetAvailablePeriods(sourcePath string) ([]string, error) {
+func (g *YearlyGenerator) GetAvailablePeriods(sourcePath string) ([]Period, error) {
 	reports, err := g.readFiles(sourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取学期报文件失败：%v", err)
+		return nil, g.Config.Locale.Errorf("读取学期报文件失败：%v", err)
 	}
 
-	yearMap := make(map[string]bool)
+	yearMap := make(map[string]*Period)
 	for _, report := range reports {
 		// 从文件名中提取年份
 		base := filepath.Base(report.FilePath)
 		yearRegex := regexp.MustCompile(`^(\d{4})`)
 		match := yearRegex.FindString(base)
-		if match != "" {
-			yearMap[match] = true
+		if match == "" {
+			continue
 		}
-	}
 
-	var years []string
-	for year := range yearMap {
-		years = append(years, year)
+		if _, ok := yearMap[match]; !ok {
+			year, _ := strconv.Atoi(match)
+			yearMap[match] = &Period{
+				Kind:      "y",
+				Key:       match,
+				Label:     g.Config.Locale.Sprintf("%s 学年", fmt.Sprintf("%d - %d", year, year+1)),
+				Target:    g.terms().YearlyFile(match),
+				Generated: g.targetExists(g.terms().YearlyFile(match)),
+			}
+			// 学年从当年秋季学期开始，到次年春季学期结束
+			start := time.Date(year, time.August, 1, 0, 0, 0, 0, time.UTC)
+			extendPeriod(yearMap[match], start, start.AddDate(1, 0, -1))
+			continue
+		}
+		yearMap[match].Sources++
 	}
 
-	// 对年份进行排序
-	sort.Strings(years)
-	return years, nil
+	return sortedPeriods(yearMap), nil
 }